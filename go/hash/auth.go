@@ -1,12 +1,31 @@
 package hash
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"strconv"
+
 	"github.com/cosmos/cosmos-sdk/crypto/keys/eth/ethsecp256k1"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/secp256k1"
 )
 
+var (
+	// ErrInvalidMsgLength indicates the msg to sign or verify is not a 32 bytes digest
+	ErrInvalidMsgLength = errors.New("invalid msg length")
+	// ErrInvalidSignatureLength indicates the signature is not 65 bytes of [R || S || V]
+	ErrInvalidSignatureLength = errors.New("invalid signature length")
+	// ErrInvalidRecoveryID indicates the V of the signature is none of 0, 1, 27 and 28
+	ErrInvalidRecoveryID = errors.New("invalid signature recovery id")
+	// ErrInvalidPrivKey indicates the private key to sign with is nil
+	ErrInvalidPrivKey = errors.New("invalid private key")
+	// ErrSignatureMismatch indicates the signature is not signed by the expected address
+	ErrSignatureMismatch = errors.New("signature mismatch")
+)
+
 // RecoverAddr recovers the sender address from msg and signature
 func RecoverAddr(msg []byte, sig []byte) (sdk.AccAddress, ethsecp256k1.PubKey, error) {
 	pubKeyByte, err := secp256k1.RecoverPubkey(msg, sig)
@@ -20,3 +39,92 @@ func RecoverAddr(msg []byte, sig []byte) (sdk.AccAddress, ethsecp256k1.PubKey, e
 	recoverAcc := sdk.AccAddress(pk.Address().Bytes())
 	return recoverAcc, pk, nil
 }
+
+// SignMsg signs the 32 bytes msg digest, such as the result of GetMsgToSignInGNFD1Auth, and returns the 65 bytes
+// signature [R || S || V] whose V is 0 or 1, which can be recovered by RecoverAddr.
+// The raw key and ethsecp256k1.PrivKey can be converted by ethcrypto.ToECDSA and PrivKey.ToECDSA.
+func SignMsg(privKey *ecdsa.PrivateKey, msg []byte) ([]byte, error) {
+	if len(msg) != ethcrypto.DigestLength {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidMsgLength, len(msg))
+	}
+	if privKey == nil || privKey.D == nil {
+		return nil, ErrInvalidPrivKey
+	}
+	return ethcrypto.Sign(msg, privKey)
+}
+
+// VerifySignature verifies the signature of the 32 bytes msg digest is signed by the expected address,
+// the V of the signature can be either 0/1 or 27/28
+func VerifySignature(msg []byte, sig []byte, expected sdk.AccAddress) error {
+	if len(msg) != ethcrypto.DigestLength {
+		return fmt.Errorf("%w: %d", ErrInvalidMsgLength, len(msg))
+	}
+	normalized, err := normalizeSignature(sig)
+	if err != nil {
+		return err
+	}
+
+	addr, _, err := RecoverAddr(msg, normalized)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(addr, expected) {
+		return fmt.Errorf("%w: recovered %s, expected %s", ErrSignatureMismatch, addr.String(), expected.String())
+	}
+	return nil
+}
+
+// normalizeSignature checks the length of the signature and returns a copy of it whose V is 0 or 1,
+// the signature of the caller is not changed
+func normalizeSignature(sig []byte) ([]byte, error) {
+	if len(sig) != ethcrypto.SignatureLength {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSignatureLength, len(sig))
+	}
+	recoveryID := sig[ethcrypto.RecoveryIDOffset]
+	switch recoveryID {
+	case 0, 1:
+	case 27, 28:
+		recoveryID -= 27
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrInvalidRecoveryID, recoveryID)
+	}
+	normalized := make([]byte, ethcrypto.SignatureLength)
+	copy(normalized, sig)
+	normalized[ethcrypto.RecoveryIDOffset] = recoveryID
+	return normalized, nil
+}
+
+// personalSignPrefix is the prefix of the EIP-191 personal_sign message
+const personalSignPrefix = "\x19Ethereum Signed Message:\n"
+
+// GetPersonalSignDigest returns the keccak256 digest of the EIP-191 personal_sign message, which is
+// "\x19Ethereum Signed Message:\n" + len(msg) + msg
+func GetPersonalSignDigest(msg []byte) []byte {
+	return ethcrypto.Keccak256([]byte(personalSignPrefix+strconv.Itoa(len(msg))), msg)
+}
+
+// SignPersonalMsg signs the msg in the way of the eth personal_sign used by the wallets, the V of the returned
+// signature is 27 or 28
+func SignPersonalMsg(privKey *ecdsa.PrivateKey, msg []byte) ([]byte, error) {
+	sig, err := SignMsg(privKey, GetPersonalSignDigest(msg))
+	if err != nil {
+		return nil, err
+	}
+	sig[ethcrypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+// RecoverPersonalSignAddr recovers the signer address of the eth personal_sign signature of msg,
+// the V of the signature can be either 0/1 or 27/28
+func RecoverPersonalSignAddr(msg []byte, sig []byte) (sdk.AccAddress, ethsecp256k1.PubKey, error) {
+	normalized, err := normalizeSignature(sig)
+	if err != nil {
+		return nil, ethsecp256k1.PubKey{}, err
+	}
+	return RecoverAddr(GetPersonalSignDigest(msg), normalized)
+}
+
+// VerifyPersonalSignature verifies the eth personal_sign signature of msg is signed by the expected address
+func VerifyPersonalSignature(msg []byte, sig []byte, expected sdk.AccAddress) error {
+	return VerifySignature(GetPersonalSignDigest(msg), sig, expected)
+}
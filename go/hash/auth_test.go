@@ -0,0 +1,98 @@
+package hash
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/eth/ethsecp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerifySignature(t *testing.T) {
+	privKey, err := ethsecp256k1.GenPrivKey()
+	assert.Nil(t, err)
+	addr := sdk.AccAddress(privKey.PubKey().Address())
+	msg := ethcrypto.Keccak256([]byte("test sign msg"))
+
+	ecdsaKey, err := privKey.ToECDSA()
+	assert.Nil(t, err)
+	rawKey, err := ethcrypto.ToECDSA(privKey.Key)
+	assert.Nil(t, err)
+	for _, key := range []*ecdsa.PrivateKey{ecdsaKey, rawKey} {
+		sig, err := SignMsg(key, msg)
+		assert.Nil(t, err)
+		assert.Equal(t, 65, len(sig))
+
+		recoverAddr, pubKey, err := RecoverAddr(msg, sig)
+		assert.Nil(t, err)
+		assert.Equal(t, addr, recoverAddr)
+		assert.True(t, pubKey.Equals(privKey.PubKey()))
+		assert.Nil(t, VerifySignature(msg, sig, addr))
+
+		// the V of 27/28 is accepted and the signature of the caller is not changed
+		ethSig := append([]byte{}, sig...)
+		ethSig[64] += 27
+		assert.Nil(t, VerifySignature(msg, ethSig, addr))
+		assert.Equal(t, sig[64]+27, ethSig[64])
+	}
+
+	sig, err := SignMsg(ecdsaKey, msg)
+	assert.Nil(t, err)
+	otherKey, err := ethsecp256k1.GenPrivKey()
+	assert.Nil(t, err)
+	assert.ErrorIs(t, VerifySignature(msg, sig, sdk.AccAddress(otherKey.PubKey().Address())), ErrSignatureMismatch)
+	assert.ErrorIs(t, VerifySignature(ethcrypto.Keccak256([]byte("other msg")), sig, addr), ErrSignatureMismatch)
+
+	assert.ErrorIs(t, VerifySignature(msg, sig[:64], addr), ErrInvalidSignatureLength)
+	assert.ErrorIs(t, VerifySignature(msg[:31], sig, addr), ErrInvalidMsgLength)
+	badSig := append([]byte{}, sig...)
+	badSig[64] = 2
+	assert.ErrorIs(t, VerifySignature(msg, badSig, addr), ErrInvalidRecoveryID)
+
+	_, err = SignMsg(ecdsaKey, []byte("not a digest"))
+	assert.ErrorIs(t, err, ErrInvalidMsgLength)
+	_, err = SignMsg(nil, msg)
+	assert.ErrorIs(t, err, ErrInvalidPrivKey)
+	_, err = SignMsg(&ecdsa.PrivateKey{}, msg)
+	assert.ErrorIs(t, err, ErrInvalidPrivKey)
+}
+
+func TestPersonalSign(t *testing.T) {
+	privKey := &ethsecp256k1.PrivKey{Key: ethcrypto.Keccak256([]byte("personal sign test key"))}
+	addr := sdk.AccAddress(privKey.PubKey().Address())
+	msg := []byte("hello greenfield")
+	ecdsaKey, err := privKey.ToECDSA()
+	assert.Nil(t, err)
+
+	expectedDigest := ethcrypto.Keccak256([]byte("\x19Ethereum Signed Message:\n16hello greenfield"))
+	assert.Equal(t, expectedDigest, GetPersonalSignDigest(msg))
+	assert.Equal(t, accounts.TextHash(msg), GetPersonalSignDigest(msg))
+
+	sig, err := SignPersonalMsg(ecdsaKey, msg)
+	assert.Nil(t, err)
+	assert.True(t, sig[64] == 27 || sig[64] == 28)
+
+	recoverAddr, pubKey, err := RecoverPersonalSignAddr(msg, sig)
+	assert.Nil(t, err)
+	assert.Equal(t, addr, recoverAddr)
+	assert.True(t, pubKey.Equals(privKey.PubKey()))
+	assert.Nil(t, VerifyPersonalSignature(msg, sig, addr))
+
+	// the signature of 0/1 is accepted either
+	sig[64] -= 27
+	recoverAddr, _, err = RecoverPersonalSignAddr(msg, sig)
+	assert.Nil(t, err)
+	assert.Equal(t, addr, recoverAddr)
+
+	// the digest without the prefix is not the signed one
+	rawAddr, _, err := RecoverAddr(ethcrypto.Keccak256(msg), sig)
+	assert.Nil(t, err)
+	assert.NotEqual(t, addr, rawAddr)
+	assert.ErrorIs(t, VerifyPersonalSignature([]byte("other msg"), sig, addr), ErrSignatureMismatch)
+
+	_, _, err = RecoverPersonalSignAddr(msg, sig[:64])
+	assert.ErrorIs(t, err, ErrInvalidSignatureLength)
+}
@@ -0,0 +1,160 @@
+package hash
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	sha256simd "github.com/minio/sha256-simd"
+)
+
+// the names of the built-in checksum backends
+const (
+	// StdlibChecksumBackend uses crypto/sha256, it is the default backend
+	StdlibChecksumBackend = "stdlib"
+	// SIMDChecksumBackend uses github.com/minio/sha256-simd, which is accelerated by the SHA extensions and AVX-512
+	SIMDChecksumBackend = "sha256-simd"
+	// StdlibMultiBufferChecksumBackend is the multi-buffer mode of StdlibChecksumBackend
+	StdlibMultiBufferChecksumBackend = StdlibChecksumBackend + multiBufferSuffix
+	// SIMDMultiBufferChecksumBackend is the multi-buffer mode of SIMDChecksumBackend
+	SIMDMultiBufferChecksumBackend = SIMDChecksumBackend + multiBufferSuffix
+
+	multiBufferSuffix = "-multibuffer"
+)
+
+var (
+	// ErrUnknownChecksumBackend indicates the checksum backend has not been registered
+	ErrUnknownChecksumBackend = errors.New("unknown checksum backend")
+	// ErrDuplicateChecksumBackend indicates the name of the checksum backend has been registered
+	ErrDuplicateChecksumBackend = errors.New("duplicate checksum backend")
+)
+
+// ChecksumBackend computes the sha256 checksums of GenerateChecksum, GenerateIntegrityHash and the hashing of
+// segments and pieces. A backend must produce the same checksums as crypto/sha256.
+type ChecksumBackend interface {
+	// Name returns the name to register and select the backend
+	Name() string
+	// Sum256 returns the sha256 checksum of data
+	Sum256(data []byte) [sha256.Size]byte
+}
+
+// MultiChecksumBackend is implemented by the backends which compute the checksums of several buffers at once,
+// it is used to hash the EC pieces of a segment
+type MultiChecksumBackend interface {
+	ChecksumBackend
+	// SumMany writes the sha256 checksum of data[i] to checksums[i], whose length is sha256.Size
+	SumMany(data [][]byte, checksums [][]byte)
+}
+
+type stdlibBackend struct{}
+
+func (stdlibBackend) Name() string { return StdlibChecksumBackend }
+
+func (stdlibBackend) Sum256(data []byte) [sha256.Size]byte { return sha256.Sum256(data) }
+
+type simdBackend struct{}
+
+func (simdBackend) Name() string { return SIMDChecksumBackend }
+
+func (simdBackend) Sum256(data []byte) [sha256.Size]byte { return sha256simd.Sum256(data) }
+
+type multiBufferBackend struct {
+	ChecksumBackend
+}
+
+// NewMultiBufferChecksumBackend wraps the backend into the multi-buffer mode, which hashes the buffers passed to
+// SumMany concurrently. The name of the wrapped backend has the suffix "-multibuffer".
+func NewMultiBufferChecksumBackend(backend ChecksumBackend) MultiChecksumBackend {
+	return &multiBufferBackend{ChecksumBackend: backend}
+}
+
+func (b *multiBufferBackend) Name() string {
+	return b.ChecksumBackend.Name() + multiBufferSuffix
+}
+
+func (b *multiBufferBackend) SumMany(data [][]byte, checksums [][]byte) {
+	wg := &sync.WaitGroup{}
+	wg.Add(len(data))
+	for index := range data {
+		go func(index int) {
+			defer wg.Done()
+			checksum := b.Sum256(data[index])
+			copy(checksums[index], checksum[:])
+		}(index)
+	}
+	wg.Wait()
+}
+
+var (
+	backendsMtx sync.RWMutex
+	backends    = map[string]ChecksumBackend{}
+	// currentBackend is the backend in use, it is read on every checksum computing
+	currentBackend atomic.Pointer[checksumBackendHolder]
+)
+
+type checksumBackendHolder struct {
+	backend ChecksumBackend
+}
+
+func init() {
+	for _, backend := range []ChecksumBackend{
+		stdlibBackend{},
+		simdBackend{},
+		NewMultiBufferChecksumBackend(stdlibBackend{}),
+		NewMultiBufferChecksumBackend(simdBackend{}),
+	} {
+		backends[backend.Name()] = backend
+	}
+	currentBackend.Store(&checksumBackendHolder{backend: stdlibBackend{}})
+}
+
+// RegisterChecksumBackend registers the backend by its name, so it can be selected by UseChecksumBackend
+func RegisterChecksumBackend(backend ChecksumBackend) error {
+	backendsMtx.Lock()
+	defer backendsMtx.Unlock()
+	if _, ok := backends[backend.Name()]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateChecksumBackend, backend.Name())
+	}
+	backends[backend.Name()] = backend
+	return nil
+}
+
+// unregisterChecksumBackend removes the registered backend of the name, it is used by the tests to clean up
+func unregisterChecksumBackend(name string) {
+	backendsMtx.Lock()
+	defer backendsMtx.Unlock()
+	delete(backends, name)
+}
+
+// UseChecksumBackend selects the registered backend of the name for all the checksum computing,
+// StdlibChecksumBackend is used by default
+func UseChecksumBackend(name string) error {
+	backendsMtx.RLock()
+	backend, ok := backends[name]
+	backendsMtx.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownChecksumBackend, name)
+	}
+	currentBackend.Store(&checksumBackendHolder{backend: backend})
+	return nil
+}
+
+// CurrentChecksumBackend returns the backend in use
+func CurrentChecksumBackend() ChecksumBackend {
+	return currentBackend.Load().backend
+}
+
+// sumChecksums writes the checksums of the data to checksums by the backend in use
+func sumChecksums(data [][]byte, checksums [][]byte) {
+	backend := CurrentChecksumBackend()
+	if multiBackend, ok := backend.(MultiChecksumBackend); ok {
+		multiBackend.SumMany(data, checksums)
+		return
+	}
+	for index := range data {
+		checksum := backend.Sum256(data[index])
+		copy(checksums[index], checksum[:])
+	}
+}
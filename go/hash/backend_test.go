@@ -0,0 +1,94 @@
+package hash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-common/go/redundancy"
+)
+
+type mockChecksumBackend struct {
+	stdlibBackend
+}
+
+func (mockChecksumBackend) Name() string { return "mock" }
+
+// useTestChecksumBackend switches to the backend of name and restores the stdlib backend after the test
+func useTestChecksumBackend(t *testing.T, name string) {
+	t.Cleanup(func() {
+		if err := UseChecksumBackend(StdlibChecksumBackend); err != nil {
+			t.Errorf("restore the stdlib backend: %v", err)
+		}
+	})
+	require.NoError(t, UseChecksumBackend(name))
+	if current := CurrentChecksumBackend().Name(); current != name {
+		t.Errorf("current backend %s, expected %s", current, name)
+	}
+}
+
+// TestChecksumBackends compares the checksums and the integrity meta of all the built-in backends with crypto/sha256
+func TestChecksumBackends(t *testing.T) {
+	if current := CurrentChecksumBackend().Name(); current != StdlibChecksumBackend {
+		t.Errorf("default backend %s, expected %s", current, StdlibChecksumBackend)
+	}
+
+	content := newTestContent(testSegmentSize*3 + 55)
+	expectedMeta, err := ComputeIntegrityMetaFromBuffer(content, testSegmentSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks)
+	require.NoError(t, err)
+
+	for _, name := range []string{StdlibChecksumBackend, SIMDChecksumBackend, StdlibMultiBufferChecksumBackend,
+		SIMDMultiBufferChecksumBackend} {
+		t.Run(name, func(t *testing.T) {
+			useTestChecksumBackend(t, name)
+			// all the sizes around the block size
+			for size := 0; size < 200; size++ {
+				checkChecksum(t, content[:size])
+			}
+			checkChecksum(t, content)
+
+			meta, err := ComputeIntegrityMetaFromBuffer(content, testSegmentSize, redundancy.DataBlocks,
+				redundancy.ParityBlocks)
+			require.NoError(t, err)
+			if !reflect.DeepEqual(expectedMeta, meta) {
+				t.Errorf("meta mismatch, expected %+v, got %+v", expectedMeta, meta)
+			}
+		})
+	}
+}
+
+func TestRegisterChecksumBackend(t *testing.T) {
+	content := newTestContent(testSegmentSize)
+	for _, c := range []struct {
+		name string
+		run  func() error
+		err  error
+	}{
+		{"use unknown", func() error { return UseChecksumBackend("unknown") }, ErrUnknownChecksumBackend},
+		{"register duplicate", func() error { return RegisterChecksumBackend(simdBackend{}) },
+			ErrDuplicateChecksumBackend},
+	} {
+		if err := c.run(); !errors.Is(err, c.err) {
+			t.Errorf("%s: returned %v, expected %v", c.name, err, c.err)
+		}
+	}
+
+	require.NoError(t, RegisterChecksumBackend(mockChecksumBackend{}))
+	t.Cleanup(func() { unregisterChecksumBackend("mock") })
+	useTestChecksumBackend(t, "mock")
+	checkChecksum(t, content)
+}
+
+// checkChecksum compares the checksum of data by the current backend with crypto/sha256
+func checkChecksum(t *testing.T, data []byte) {
+	expected := sha256.Sum256(data)
+	if checksum := GenerateChecksum(data); !bytes.Equal(expected[:], checksum) {
+		t.Errorf("checksum %x of %d bytes by %s, expected %x", checksum, len(data), CurrentChecksumBackend().Name(),
+			expected)
+	}
+}
@@ -0,0 +1,239 @@
+package hash
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+)
+
+// BatchObject is an object to be hashed by BatchHasher, the object is read from Reader, or from the file of Path
+// if Reader is nil
+type BatchObject struct {
+	Name   string
+	Reader io.Reader
+	Path   string
+}
+
+// BatchResult is the integrity meta of the object of Name, or the error of hashing it
+type BatchResult struct {
+	Name string
+	Meta *IntegrityMeta
+	Err  error
+}
+
+// BatchHasher hashes many objects over one bounded worker pool, the segments of all the objects are scheduled
+// to the same workers, so the small objects are hashed in parallel with each other
+type BatchHasher struct {
+	segmentSize  int64
+	dataShards   int
+	parityShards int
+	o            *hashOptions
+}
+
+// NewBatchHasher creates a BatchHasher, the opts configure the worker number, the in-flight segments shared by all
+// the objects, the logger and the redundancy type
+func NewBatchHasher(segmentSize int64, dataShards, parityShards int, opts ...Option) (*BatchHasher, error) {
+	if segmentSize <= 0 {
+		return nil, ErrInvalidSegmentSize
+	}
+	o := newHashOptions(opts...)
+	if err := checkRedundancyType(o.redundancyType); err != nil {
+		return nil, err
+	}
+	return &BatchHasher{
+		segmentSize:  segmentSize,
+		dataShards:   dataShards,
+		parityShards: parityShards,
+		o:            o,
+	}, nil
+}
+
+// batchObjectState collects the hashes of the segments of an object, the object is finished once it has been read
+// and all its segments have been hashed
+type batchObjectState struct {
+	mtx            sync.Mutex
+	name           string
+	segChecksums   [][]byte
+	pieceChecksums [][][]byte
+	contentLen     int64
+	// pending is the number of segments not hashed, plus 1 while the object is being read
+	pending int
+	err     error
+}
+
+type batchSegmentJob struct {
+	object *batchObjectState
+	segmentJob
+}
+
+// Hash hashes the objects and sends the result of each object to the returned channel once the object is finished,
+// the results are in the order of finishing and the channel is closed after all the objects are finished.
+// The channel is buffered for all the results, so the workers never block even if the results are not received.
+func (b *BatchHasher) Hash(ctx context.Context, objects []BatchObject) <-chan BatchResult {
+	results := make(chan BatchResult, len(objects))
+	inflightNum := b.o.inflightSegments(b.segmentSize)
+	inflight := make(chan struct{}, inflightNum)
+	jobChan := make(chan batchSegmentJob, inflightNum)
+	objectChan := make(chan BatchObject, len(objects))
+	for _, object := range objects {
+		objectChan <- object
+	}
+	close(objectChan)
+
+	workerWg := &sync.WaitGroup{}
+	for i := 0; i < b.o.workerNum; i++ {
+		workerWg.Add(1)
+		go func() {
+			defer workerWg.Done()
+			for job := range jobChan {
+				b.hashSegment(ctx, job, results)
+				putSegmentBuffer(job.buffer)
+				<-inflight
+			}
+		}()
+	}
+
+	// the objects are read by the same number of readers as the workers
+	readerWg := &sync.WaitGroup{}
+	for i := 0; i < b.o.workerNum; i++ {
+		readerWg.Add(1)
+		go func() {
+			defer readerWg.Done()
+			for object := range objectChan {
+				b.readObject(ctx, object, inflight, jobChan, results)
+			}
+		}()
+	}
+
+	go func() {
+		readerWg.Wait()
+		close(jobChan)
+		workerWg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+// readObject reads the segments of the object and dispatches them to the workers
+func (b *BatchHasher) readObject(ctx context.Context, object BatchObject, inflight chan struct{},
+	jobChan chan<- batchSegmentJob, results chan<- BatchResult,
+) {
+	state := &batchObjectState{name: object.Name, pending: 1}
+	defer b.finishPending(state, results)
+
+	reader := object.Reader
+	if reader == nil {
+		f, err := os.Open(object.Path)
+		if err != nil {
+			b.o.logger.Error().Msg("failed to open file:" + err.Error())
+			state.setErr(err)
+			return
+		}
+		defer f.Close()
+		reader = f
+	}
+
+	segReader := NewSegmentReader(reader, b.segmentSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			state.setErr(err)
+			return
+		}
+		select {
+		case inflight <- struct{}{}:
+		case <-ctx.Done():
+			state.setErr(ctx.Err())
+			return
+		}
+		segBuffer := getSegmentBuffer(b.segmentSize)
+		seg, err := segReader.NextInto(*segBuffer)
+		if err == nil && state.failed() {
+			// stop reading since a segment of the object failed to be hashed
+			err = io.EOF
+		}
+		if err != nil {
+			putSegmentBuffer(segBuffer)
+			<-inflight
+			if err != io.EOF {
+				b.o.logger.Error().Msg("failed to read content:" + err.Error())
+				state.setErr(err)
+			}
+			return
+		}
+
+		state.mtx.Lock()
+		state.segChecksums = append(state.segChecksums, nil)
+		state.pieceChecksums = append(state.pieceChecksums, nil)
+		state.contentLen += int64(len(seg.Data))
+		state.pending++
+		state.mtx.Unlock()
+		// the job channel never blocks since its capacity is the same as the in-flight limit
+		jobChan <- batchSegmentJob{object: state, segmentJob: segmentJob{SegmentInfo: seg, buffer: segBuffer}}
+	}
+}
+
+// hashSegment computes the hashes of the segment and stores them at the segment index of the object
+func (b *BatchHasher) hashSegment(ctx context.Context, job batchSegmentJob, results chan<- BatchResult) {
+	state := job.object
+	defer b.finishPending(state, results)
+	if err := ctx.Err(); err != nil {
+		state.setErr(err)
+		return
+	}
+	if state.failed() {
+		return
+	}
+
+	checksum, pieceChecksumList, err := computeSegmentHashes(job.SegmentInfo, b.dataShards, b.parityShards,
+		b.o.redundancyType, NopObserver{})
+	if err != nil {
+		state.setErr(err)
+		return
+	}
+	state.mtx.Lock()
+	state.segChecksums[job.SegmentID] = checksum
+	state.pieceChecksums[job.SegmentID] = pieceChecksumList
+	state.mtx.Unlock()
+}
+
+// finishPending decreases the pending number of the object, the result is sent once nothing is pending
+func (b *BatchHasher) finishPending(state *batchObjectState, results chan<- BatchResult) {
+	state.mtx.Lock()
+	state.pending--
+	finished := state.pending == 0
+	state.mtx.Unlock()
+	if !finished {
+		return
+	}
+
+	if state.err != nil {
+		results <- BatchResult{Name: state.name, Err: state.err}
+		return
+	}
+	encodeDataHash := make([][][]byte, b.dataShards+b.parityShards)
+	for index := range encodeDataHash {
+		encodeDataHash[index] = make([][]byte, len(state.pieceChecksums))
+		for segmentID, pieceChecksumList := range state.pieceChecksums {
+			encodeDataHash[index][segmentID] = pieceChecksumList[index]
+		}
+	}
+	results <- BatchResult{
+		Name: state.name,
+		Meta: newIntegrityMeta(state.segChecksums, encodeDataHash, state.contentLen, b.o.redundancyType),
+	}
+}
+
+func (s *batchObjectState) setErr(err error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+func (s *batchObjectState) failed() bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.err != nil
+}
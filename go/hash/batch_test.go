@@ -0,0 +1,88 @@
+package hash
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-common/go/redundancy"
+)
+
+func TestBatchHasher(t *testing.T) {
+	dir := t.TempDir()
+	contents := make(map[string][]byte)
+	var objects []BatchObject
+	for i := 0; i < 50; i++ {
+		content := newTestContent(rand.Intn(testSegmentSize * 5))
+		name := fmt.Sprintf("object-%d", i)
+		contents[name] = content
+		if i%2 == 0 {
+			objects = append(objects, BatchObject{Name: name, Reader: iotest.HalfReader(bytes.NewReader(content))})
+			continue
+		}
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, content, 0o600))
+		objects = append(objects, BatchObject{Name: name, Path: path})
+	}
+	readErr := errors.New("mock read error")
+	objects = append(objects,
+		BatchObject{Name: "read-error", Reader: io.MultiReader(bytes.NewReader(newTestContent(testSegmentSize*2)),
+			iotest.ErrReader(readErr))},
+		BatchObject{Name: "no-file", Path: filepath.Join(dir, "no-file")})
+	expectedErrs := map[string]error{"read-error": readErr, "no-file": os.ErrNotExist}
+
+	hasher, err := NewBatchHasher(testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks, WithWorkerNum(4),
+		WithMaxInflightSegments(3))
+	require.NoError(t, err)
+	// the results are drained before checking, so the hashing goroutines are not left blocked on a failure
+	results := make(map[string]BatchResult)
+	for result := range hasher.Hash(context.Background(), objects) {
+		if _, ok := results[result.Name]; ok {
+			t.Errorf("%s: duplicated result", result.Name)
+		}
+		results[result.Name] = result
+	}
+	require.Len(t, results, len(objects))
+	for name, result := range results {
+		if expectedErr, ok := expectedErrs[name]; ok {
+			if !errors.Is(result.Err, expectedErr) {
+				t.Errorf("%s: returned %v, expected %v", name, result.Err, expectedErr)
+			}
+			continue
+		}
+		if result.Err != nil {
+			t.Errorf("%s: returned %v", name, result.Err)
+			continue
+		}
+		expected, err := ComputeIntegrityMetaFromBuffer(contents[name], testSegmentSize, redundancy.DataBlocks,
+			redundancy.ParityBlocks, WithSerial(true))
+		require.NoError(t, err)
+		if !reflect.DeepEqual(expected, result.Meta) {
+			t.Errorf("%s: meta mismatch, expected %+v, got %+v", name, expected, result.Meta)
+		}
+	}
+
+	// all the objects fail once the ctx is canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for result := range hasher.Hash(ctx, objects[:10]) {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("%s: returned %v after canceled, expected %v", result.Name, result.Err, context.Canceled)
+		}
+	}
+
+	_, err = NewBatchHasher(0, redundancy.DataBlocks, redundancy.ParityBlocks)
+	if !errors.Is(err, ErrInvalidSegmentSize) {
+		t.Errorf("returned %v of segment size 0, expected %v", err, ErrInvalidSegmentSize)
+	}
+}
@@ -0,0 +1,217 @@
+package hash
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/bnb-chain/greenfield-common/go/redundancy"
+)
+
+const (
+	benchSegmentSize = 1024 * 1024
+	benchContentSize = 16 * benchSegmentSize
+)
+
+func benchContent() []byte {
+	content := make([]byte, benchContentSize)
+	rand.New(rand.NewSource(1)).Read(content)
+	return content
+}
+
+// baselineIntegrityHash hashes the content in the way before the segment buffers and the erasure encoders are
+// pooled, which is what ComputeIntegrityHashSerial and IntegrityHasher.ReadFrom did: SegmentReader.Next allocates
+// the buffer of each segment, redundancy.EncodeRawSegment allocates the shards and sha256.New allocates each checksum
+func baselineIntegrityHash(reader io.Reader, segmentSize int64, dataShards, parityShards int) ([][]byte, error) {
+	segChecksums := make([][]byte, 0)
+	pieceChecksums := make([][][]byte, dataShards+parityShards)
+	segReader := NewSegmentReader(reader, segmentSize)
+	for {
+		seg, err := segReader.Next()
+		if err == io.EOF {
+			return generateIntegrityHashList(segChecksums, pieceChecksums), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		segChecksums = append(segChecksums, baselineChecksum(seg.Data))
+		shards, err := redundancy.EncodeRawSegment(seg.Data, dataShards, parityShards)
+		if err != nil {
+			return nil, err
+		}
+		for index, shard := range shards {
+			pieceChecksums[index] = append(pieceChecksums[index], baselineChecksum(shard))
+		}
+	}
+}
+
+// baselineVerify verifies the segments in the way of VerifyingReader before the segment buffer is pooled
+func baselineVerify(reader io.Reader, segmentSize int64, checksums [][]byte) error {
+	segReader := NewSegmentReader(reader, segmentSize)
+	for index := 0; ; index++ {
+		seg, err := segReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if index >= len(checksums) || !bytes.Equal(baselineChecksum(seg.Data), checksums[index]) {
+			return errors.New("segment checksum mismatch")
+		}
+		if _, err = io.Copy(io.Discard, bytes.NewReader(seg.Data)); err != nil {
+			return err
+		}
+	}
+}
+
+func baselineChecksum(data []byte) []byte {
+	hash := sha256.New()
+	hash.Write(data)
+	return hash.Sum(nil)
+}
+
+// BenchmarkComputeIntegrityHash compares the allocations of the serial computing with the baseline
+func BenchmarkComputeIntegrityHash(b *testing.B) {
+	content := benchContent()
+	expected, _, _, err := ComputeIntegrityHashSerial(bytes.NewReader(content), benchSegmentSize,
+		redundancy.DataBlocks, redundancy.ParityBlocks)
+	if err != nil {
+		b.Fatal(err)
+	}
+	baseline, err := baselineIntegrityHash(bytes.NewReader(content), benchSegmentSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks)
+	if err != nil || !equalChecksums(expected, baseline) {
+		b.Fatalf("the baseline differs from ComputeIntegrityHashSerial, err: %v", err)
+	}
+
+	b.Run("baseline", func(b *testing.B) {
+		b.SetBytes(benchContentSize)
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			if _, err := baselineIntegrityHash(bytes.NewReader(content), benchSegmentSize, redundancy.DataBlocks,
+				redundancy.ParityBlocks); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("pooled", func(b *testing.B) {
+		b.SetBytes(benchContentSize)
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			_, err := ComputeIntegrityMeta(context.Background(), bytes.NewReader(content), benchSegmentSize,
+				redundancy.DataBlocks, redundancy.ParityBlocks, WithSerial(true))
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkComputeIntegrityHashParallel(b *testing.B) {
+	content := benchContent()
+	b.SetBytes(benchContentSize)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_, err := ComputeIntegrityMeta(context.Background(), bytes.NewReader(content), benchSegmentSize,
+			redundancy.DataBlocks, redundancy.ParityBlocks)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkComputeIntegrityHashFromReaderAt(b *testing.B) {
+	content := benchContent()
+	b.SetBytes(benchContentSize)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_, err := ComputeIntegrityMetaFromReaderAt(context.Background(), bytes.NewReader(content), benchContentSize,
+			benchSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkIntegrityHasher compares the allocations of IntegrityHasher.ReadFrom with the baseline
+func BenchmarkIntegrityHasher(b *testing.B) {
+	content := benchContent()
+	b.Run("baseline", func(b *testing.B) {
+		b.SetBytes(benchContentSize)
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			if _, err := baselineIntegrityHash(bytes.NewReader(content), benchSegmentSize, redundancy.DataBlocks,
+				redundancy.ParityBlocks); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("pooled", func(b *testing.B) {
+		b.SetBytes(benchContentSize)
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			hasher := NewHasher(benchSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+			hasher.Init()
+			if _, err := hasher.ReadFrom(bytes.NewReader(content)); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := hasher.FinishMeta(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkVerifyingReader compares the allocations of VerifyingReader with the baseline
+func BenchmarkVerifyingReader(b *testing.B) {
+	content := benchContent()
+	var checksums [][]byte
+	for offset := 0; offset < len(content); offset += benchSegmentSize {
+		checksums = append(checksums, GenerateChecksum(content[offset:offset+benchSegmentSize]))
+	}
+
+	b.Run("baseline", func(b *testing.B) {
+		b.SetBytes(benchContentSize)
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			if err := baselineVerify(bytes.NewReader(content), benchSegmentSize, checksums); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("pooled", func(b *testing.B) {
+		b.SetBytes(benchContentSize)
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			reader := NewVerifyingReader(bytes.NewReader(content), benchSegmentSize, checksums)
+			if _, err := io.Copy(io.Discard, reader); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkChecksumBackends(b *testing.B) {
+	defer UseChecksumBackend(StdlibChecksumBackend)
+	content := benchContent()[:benchSegmentSize]
+	for _, name := range []string{StdlibChecksumBackend, SIMDChecksumBackend} {
+		b.Run(name, func(b *testing.B) {
+			if err := UseChecksumBackend(name); err != nil {
+				b.Fatal(err)
+			}
+			b.SetBytes(benchSegmentSize)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				GenerateChecksum(content)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package hash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// ErrInvalidChecksumList indicates the checksum list does not match the integrity hash
+	ErrInvalidChecksumList = errors.New("invalid checksum list")
+	// ErrIndexOutOfRange indicates the challenged index is not in the checksum list
+	ErrIndexOutOfRange = errors.New("piece index out of range")
+	// ErrPieceMismatch indicates the piece data does not match the checksum at the challenged index
+	ErrPieceMismatch = errors.New("piece data and piece hash are inconsistent")
+	// ErrNoChallengeTarget indicates the object has no data to be challenged
+	ErrNoChallengeTarget = errors.New("no challenge target")
+	// ErrInvalidChallengeSeed indicates the challenge seed is not ChallengeSeedLength bytes
+	ErrInvalidChallengeSeed = errors.New("invalid challenge seed")
+	// ErrInvalidRandaoMix indicates the randao mix is neither empty nor ChallengeSeedLength bytes
+	ErrInvalidRandaoMix = errors.New("invalid randao mix")
+)
+
+// PrimaryRedundancyIndex is the redundancy index of the primary SP which stores the segments
+const PrimaryRedundancyIndex = -1
+
+// PieceError is the failure of verifying the piece at Index, Err is ErrIndexOutOfRange or ErrPieceMismatch
+type PieceError struct {
+	Index int
+	Err   error
+}
+
+func (e *PieceError) Error() string {
+	return fmt.Sprintf("piece %d: %s", e.Index, e.Err.Error())
+}
+
+func (e *PieceError) Unwrap() error {
+	return e.Err
+}
+
+// ChallengeVerifier verifies the pieces of one piece list, the checksum list is verified against the integrity hash
+// once when the verifier is created instead of on every challenge as ChallengePieceHash
+type ChallengeVerifier struct {
+	checksumList [][]byte
+}
+
+// NewChallengeVerifier creates a ChallengeVerifier of the checksum list, ErrInvalidChecksumList is returned if the
+// checksum list does not match the integrity hash
+func NewChallengeVerifier(integrityHash []byte, checksumList [][]byte) (*ChallengeVerifier, error) {
+	if err := VerifyIntegrityHash(integrityHash, checksumList); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidChecksumList, err.Error())
+	}
+	return &ChallengeVerifier{checksumList: append([][]byte{}, checksumList...)}, nil
+}
+
+// VerifyPiece verifies the piece data at index, a *PieceError is returned if failed
+func (v *ChallengeVerifier) VerifyPiece(index int, pieceData []byte) error {
+	if index < 0 || index >= len(v.checksumList) {
+		return &PieceError{Index: index, Err: ErrIndexOutOfRange}
+	}
+	if !bytes.Equal(v.checksumList[index], GenerateChecksum(pieceData)) {
+		return &PieceError{Index: index, Err: ErrPieceMismatch}
+	}
+	return nil
+}
+
+// VerifyPieces verifies the pieces indexed by the piece index, the result of each index is nil if the piece is
+// verified, or a *PieceError otherwise
+func (v *ChallengeVerifier) VerifyPieces(pieces map[int][]byte) map[int]error {
+	results := make(map[int]error, len(pieces))
+	for index, pieceData := range pieces {
+		results[index] = v.VerifyPiece(index, pieceData)
+	}
+	return results
+}
+
+// ChallengeTarget describes a piece which can be challenged and the arguments of ChallengePieceHash to verify it
+type ChallengeTarget struct {
+	SegmentIndex int
+	// RedundancyIndex is PrimaryRedundancyIndex for the segments stored by the primary SP, or the index of the
+	// secondary SP which stores the EC pieces
+	RedundancyIndex int
+	// ChecksumIndex is the index of the integrity hash in the object checksums, which is 0 for the primary SP and
+	// RedundancyIndex+1 for the secondary SPs
+	ChecksumIndex int
+	// PieceIndex is the index of the challenged piece in the checksum list
+	PieceIndex int
+	// PieceSize is the size of the challenged segment or EC piece
+	PieceSize int64
+}
+
+// ChallengeTargets enumerates all the challenge targets of the object, ordered by the segment index and then the
+// redundancy index from PrimaryRedundancyIndex to dataShards+parityShards-1
+func ChallengeTargets(payloadSize, segmentSize int64, dataShards, parityShards int) ([]ChallengeTarget, error) {
+	targetNum, err := challengeTargetNum(payloadSize, segmentSize, dataShards, parityShards)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]ChallengeTarget, targetNum)
+	for index := range targets {
+		targets[index] = challengeTarget(payloadSize, segmentSize, dataShards, parityShards, index)
+	}
+	return targets, nil
+}
+
+// ChallengeSeedLength is the length of the challenge seed, which is the length of the randao mix of the block header
+const ChallengeSeedLength = 64
+
+// ChallengeSeedFromRandaoMix generates the challenge seed of the iteration from the randao mix of the block header,
+// it is the same as SeedFromRandaoMix of the greenfield challenge module. The randao mix must be empty or
+// ChallengeSeedLength bytes as validated in the block header.
+func ChallengeSeedFromRandaoMix(randaoMix []byte, iteration uint64) ([]byte, error) {
+	if len(randaoMix) != 0 && len(randaoMix) != ChallengeSeedLength {
+		return nil, fmt.Errorf("%w: length %d", ErrInvalidRandaoMix, len(randaoMix))
+	}
+	highBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(highBytes, ^uint64(0)-iteration)
+	lowBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(lowBytes, iteration)
+
+	seed := append(ethcrypto.Keccak256(highBytes), ethcrypto.Keccak256(lowBytes)...)
+	for i := range randaoMix {
+		seed[i] ^= randaoMix[i]
+	}
+	return seed, nil
+}
+
+// SelectChallengeTarget picks the challenge target of the object from the seed in the same way as the greenfield
+// challenge module, the segment index is the keccak256 of the first half of the seed mod the segment number, and
+// the redundancy index is the keccak256 of the second half mod the SP number, starting from PrimaryRedundancyIndex
+func SelectChallengeTarget(payloadSize, segmentSize int64, dataShards, parityShards int, seed []byte,
+) (ChallengeTarget, error) {
+	if _, err := challengeTargetNum(payloadSize, segmentSize, dataShards, parityShards); err != nil {
+		return ChallengeTarget{}, err
+	}
+	if len(seed) != ChallengeSeedLength {
+		return ChallengeTarget{}, fmt.Errorf("%w: length %d", ErrInvalidChallengeSeed, len(seed))
+	}
+	segmentNum := int64(SegmentCount(payloadSize, segmentSize))
+	segmentIndex := new(big.Int).Mod(new(big.Int).SetBytes(ethcrypto.Keccak256(seed[:32])), big.NewInt(segmentNum))
+	spNum := int64(dataShards + parityShards + 1)
+	spIndex := new(big.Int).Mod(new(big.Int).SetBytes(ethcrypto.Keccak256(seed[32:])), big.NewInt(spNum))
+	index := int(segmentIndex.Int64())*int(spNum) + int(spIndex.Int64())
+	return challengeTarget(payloadSize, segmentSize, dataShards, parityShards, index), nil
+}
+
+func challengeTargetNum(payloadSize, segmentSize int64, dataShards, parityShards int) (int, error) {
+	if segmentSize <= 0 {
+		return 0, ErrInvalidSegmentSize
+	}
+	if dataShards <= 0 || parityShards < 0 {
+		return 0, fmt.Errorf("invalid EC geometry: data shards %d, parity shards %d", dataShards, parityShards)
+	}
+	segmentNum := SegmentCount(payloadSize, segmentSize)
+	if segmentNum == 0 {
+		return 0, ErrNoChallengeTarget
+	}
+	return segmentNum * (dataShards + parityShards + 1), nil
+}
+
+// challengeTarget returns the challenge target at index of ChallengeTargets
+func challengeTarget(payloadSize, segmentSize int64, dataShards, parityShards int, index int) ChallengeTarget {
+	segmentIndex := index / (dataShards + parityShards + 1)
+	redundancyIndex := index%(dataShards+parityShards+1) + PrimaryRedundancyIndex
+	target := ChallengeTarget{
+		SegmentIndex:    segmentIndex,
+		RedundancyIndex: redundancyIndex,
+		ChecksumIndex:   redundancyIndex + 1,
+		PieceIndex:      segmentIndex,
+	}
+	if redundancyIndex == PrimaryRedundancyIndex {
+		target.PieceSize = SegmentDataSize(payloadSize, segmentSize, segmentIndex)
+	} else {
+		target.PieceSize = ECPieceSize(payloadSize, segmentSize, segmentIndex, dataShards)
+	}
+	return target
+}
@@ -0,0 +1,192 @@
+package hash
+
+import (
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-common/go/redundancy"
+)
+
+func TestChallengeVerifier(t *testing.T) {
+	pieces := make([][]byte, 10)
+	checksumList := make([][]byte, len(pieces))
+	for i := range pieces {
+		pieces[i] = newTestContent(1024)
+		checksumList[i] = GenerateChecksum(pieces[i])
+	}
+	integrityHash := GenerateIntegrityHash(checksumList)
+
+	if _, err := NewChallengeVerifier(integrityHash, checksumList[1:]); !errors.Is(err, ErrInvalidChecksumList) {
+		t.Errorf("returned %v of the short checksum list, expected %v", err, ErrInvalidChecksumList)
+	}
+
+	verifier, err := NewChallengeVerifier(integrityHash, checksumList)
+	require.NoError(t, err)
+	for i, piece := range pieces {
+		if err := verifier.VerifyPiece(i, piece); err != nil {
+			t.Errorf("piece %d: VerifyPiece returned %v", i, err)
+		}
+		if err := ChallengePieceHash(integrityHash, checksumList, i, piece); err != nil {
+			t.Errorf("piece %d: ChallengePieceHash returned %v", i, err)
+		}
+	}
+
+	cases := []struct {
+		index int
+		piece []byte
+		err   error
+	}{
+		{0, pieces[0], nil},
+		{3, pieces[4], ErrPieceMismatch},
+		{-1, pieces[0], ErrIndexOutOfRange},
+		{10, pieces[0], ErrIndexOutOfRange},
+	}
+	toVerify := make(map[int][]byte, len(cases))
+	for _, c := range cases {
+		toVerify[c.index] = c.piece
+	}
+	results := verifier.VerifyPieces(toVerify)
+	require.Len(t, results, len(cases))
+	for _, c := range cases {
+		if !errors.Is(results[c.index], c.err) {
+			t.Errorf("piece %d: returned %v, expected %v", c.index, results[c.index], c.err)
+		}
+	}
+
+	var pieceErr *PieceError
+	if !errors.As(results[3], &pieceErr) {
+		t.Fatalf("returned %T of the mismatched piece, expected *PieceError", results[3])
+	}
+	if pieceErr.Index != 3 {
+		t.Errorf("piece error of index %d, expected 3", pieceErr.Index)
+	}
+}
+
+// TestChallengeTargets challenges all the targets of an object with the meta computed from the object
+func TestChallengeTargets(t *testing.T) {
+	content := newTestContent(testSegmentSize*2 + 333)
+	meta, err := ComputeIntegrityMetaFromBuffer(content, testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+	require.NoError(t, err)
+
+	targets, err := ChallengeTargets(int64(len(content)), testSegmentSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks)
+	require.NoError(t, err)
+	require.Len(t, targets, 3*(redundancy.DataBlocks+redundancy.ParityBlocks+1))
+	for _, target := range targets {
+		segmentData := content[target.SegmentIndex*testSegmentSize : target.SegmentIndex*testSegmentSize+
+			int(SegmentDataSize(int64(len(content)), testSegmentSize, target.SegmentIndex))]
+		pieceData := segmentData
+		checksumList := meta.SegmentChecksums
+		if target.RedundancyIndex != PrimaryRedundancyIndex {
+			pieces, err := redundancy.EncodeRawSegment(append([]byte{}, segmentData...), redundancy.DataBlocks,
+				redundancy.ParityBlocks)
+			require.NoError(t, err)
+			pieceData = pieces[target.RedundancyIndex]
+			checksumList = meta.PieceChecksums[target.RedundancyIndex]
+		}
+		if target.PieceSize != int64(len(pieceData)) {
+			t.Errorf("target %+v: piece size, expected %d", target, len(pieceData))
+		}
+		err := ChallengePieceHash(meta.IntegrityHashes[target.ChecksumIndex], checksumList, target.PieceIndex, pieceData)
+		if err != nil {
+			t.Errorf("target %+v: %v", target, err)
+		}
+	}
+	if size := targets[len(targets)-1].PieceSize; size != 84 {
+		t.Errorf("last piece size %d, expected 84", size)
+	}
+}
+
+// TestSelectChallengeTarget checks the selection is the same as the greenfield challenge module, the vectors are
+// generated by SeedFromRandaoMix, RandomSegmentIndex and RandomRedundancyIndex of the challenge keeper
+func TestSelectChallengeTarget(t *testing.T) {
+	randaoMix := make([]byte, ChallengeSeedLength)
+	for i := range randaoMix {
+		randaoMix[i] = byte(i)
+	}
+	for _, c := range []struct {
+		iteration       uint64
+		seed            string
+		segmentNum      int64
+		segmentIndex    int
+		redundancyIndex int
+	}{
+		{0, "ad0af9480e63760de37c9783cf181883d0b00cfa8a3f7e194c97d5115a5363b0213a6f20f9a927d62cb869e4b061af517b274d2e2fb6d3f1c8c837b2862345f1", 3, 1, 0},
+		{0, "ad0af9480e63760de37c9783cf181883d0b00cfa8a3f7e194c97d5115a5363b0213a6f20f9a927d62cb869e4b061af517b274d2e2fb6d3f1c8c837b2862345f1", 100, 2, 0},
+		{1, "09be74a1d4c5bb133fc8221056ed925575195329161da474dd3969d2aa9814764c10de36660b9cf5a286baa2ef2b5e0057653960f3db9e80eaad2a7f8c1a2e30", 3, 2, 1},
+		{1, "09be74a1d4c5bb133fc8221056ed925575195329161da474dd3969d2aa9814764c10de36660b9cf5a286baa2ef2b5e0057653960f3db9e80eaad2a7f8c1a2e30", 100, 94, 1},
+		{7, "2e90f104f63f3ebe791bacbecc9a8c25d713697043c5da1ff6a049ceb101b275ca0f462fddeaa330ac4fc199db0cc4440ef9bfdc3eb90b084508dd4ed2ed6d78", 3, 1, 5},
+		{7, "2e90f104f63f3ebe791bacbecc9a8c25d713697043c5da1ff6a049ceb101b275ca0f462fddeaa330ac4fc199db0cc4440ef9bfdc3eb90b084508dd4ed2ed6d78", 100, 37, 5},
+	} {
+		seed, err := ChallengeSeedFromRandaoMix(randaoMix, c.iteration)
+		require.NoError(t, err)
+		if hex.EncodeToString(seed) != c.seed {
+			t.Errorf("iteration %d: seed %x, expected %s", c.iteration, seed, c.seed)
+		}
+		payloadSize := c.segmentNum*testSegmentSize - 1
+		target, err := SelectChallengeTarget(payloadSize, testSegmentSize, redundancy.DataBlocks,
+			redundancy.ParityBlocks, seed)
+		require.NoError(t, err)
+		if target.SegmentIndex != c.segmentIndex || target.RedundancyIndex != c.redundancyIndex {
+			t.Errorf("iteration %d of %d segments: selected segment %d redundancy %d, expected %d %d", c.iteration,
+				c.segmentNum, target.SegmentIndex, target.RedundancyIndex, c.segmentIndex, c.redundancyIndex)
+		}
+		allTargets, err := ChallengeTargets(payloadSize, testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+		require.NoError(t, err)
+		// the targets are enumerated by segment, and by redundancy index in each segment
+		spNum := redundancy.DataBlocks + redundancy.ParityBlocks + 1
+		enumerated := allTargets[target.SegmentIndex*spNum+target.RedundancyIndex-PrimaryRedundancyIndex]
+		if !reflect.DeepEqual(enumerated, target) {
+			t.Errorf("iteration %d: selected target %+v is not enumerated by ChallengeTargets", c.iteration, target)
+		}
+	}
+
+	seed, err := ChallengeSeedFromRandaoMix(nil, 0)
+	require.NoError(t, err)
+	if len(seed) != ChallengeSeedLength {
+		t.Errorf("seed of %d bytes from the empty randao mix, expected %d", len(seed), ChallengeSeedLength)
+	}
+}
+
+func TestChallengeTargetsError(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		run  func() error
+		err  error
+	}{
+		// the randao mix longer or shorter than the one of the block header is rejected instead of panicking
+		{"randao mix of 1 byte", func() error {
+			_, err := ChallengeSeedFromRandaoMix(make([]byte, 1), 0)
+			return err
+		}, ErrInvalidRandaoMix},
+		{"randao mix of 32 bytes", func() error {
+			_, err := ChallengeSeedFromRandaoMix(make([]byte, 32), 0)
+			return err
+		}, ErrInvalidRandaoMix},
+		{"long randao mix", func() error {
+			_, err := ChallengeSeedFromRandaoMix(make([]byte, ChallengeSeedLength+1), 0)
+			return err
+		}, ErrInvalidRandaoMix},
+		{"invalid seed", func() error {
+			_, err := SelectChallengeTarget(testSegmentSize, testSegmentSize, redundancy.DataBlocks,
+				redundancy.ParityBlocks, []byte("seed"))
+			return err
+		}, ErrInvalidChallengeSeed},
+		{"empty payload", func() error {
+			_, err := ChallengeTargets(0, testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+			return err
+		}, ErrNoChallengeTarget},
+		{"invalid segment size", func() error {
+			_, err := SelectChallengeTarget(100, 0, redundancy.DataBlocks, redundancy.ParityBlocks, nil)
+			return err
+		}, ErrInvalidSegmentSize},
+	} {
+		if err := c.run(); !errors.Is(err, c.err) {
+			t.Errorf("%s: returned %v, expected %v", c.name, err, c.err)
+		}
+	}
+}
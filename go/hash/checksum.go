@@ -2,7 +2,6 @@ package hash
 
 import (
 	"bytes"
-	"crypto/sha256"
 	"fmt"
 )
 
@@ -12,19 +11,17 @@ type SegmentInfo struct {
 	Data      []byte
 }
 
-// GenerateChecksum generates the checksum of one piece data
+// GenerateChecksum generates the checksum of one piece data by the checksum backend in use
 func GenerateChecksum(pieceData []byte) []byte {
-	hash := sha256.New()
-	hash.Write(pieceData)
-	return hash.Sum(nil)
+	checksum := CurrentChecksumBackend().Sum256(pieceData)
+	return checksum[:]
 }
 
-// GenerateIntegrityHash generates integrity hash of all piece data checksum
+// GenerateIntegrityHash generates integrity hash of all piece data checksum by the checksum backend in use
 func GenerateIntegrityHash(checksumList [][]byte) []byte {
-	hash := sha256.New()
 	checksumBytesTotal := bytes.Join(checksumList, []byte(""))
-	hash.Write(checksumBytesTotal)
-	return hash.Sum(nil)
+	checksum := CurrentChecksumBackend().Sum256(checksumBytesTotal)
+	return checksum[:]
 }
 
 // ChallengePieceHash challenge integrity hash and checksum list
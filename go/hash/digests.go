@@ -0,0 +1,77 @@
+package hash
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"hash/crc32"
+	"io"
+)
+
+// PayloadDigests contains the integrity meta and the digests of the whole payload which are needed by the upload
+type PayloadDigests struct {
+	Meta   *IntegrityMeta
+	MD5    []byte
+	SHA256 []byte
+	// CRC32C is the big-endian CRC32C of the payload, it is nil unless WithCRC32C is set
+	CRC32C []byte
+}
+
+// ContentMD5 returns the base64 encoded MD5 of the payload, which is the value of the Content-MD5 header
+func (d *PayloadDigests) ContentMD5() string {
+	return base64.StdEncoding.EncodeToString(d.MD5)
+}
+
+// ContentSHA256 returns the hex encoded SHA256 of the payload, which is the value of the X-Gnfd-Content-Sha256 header
+func (d *PayloadDigests) ContentSHA256() string {
+	return hex.EncodeToString(d.SHA256)
+}
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// crc32cWriter computes the CRC32C of the data written
+type crc32cWriter struct {
+	crc uint32
+}
+
+func (w *crc32cWriter) Write(p []byte) (int, error) {
+	w.crc = crc32.Update(w.crc, castagnoliTable, p)
+	return len(p), nil
+}
+
+// ComputePayloadDigests computes the integrity meta, the MD5, the SHA256 and optionally the CRC32C of the payload
+// in one pass over the reader. The opts are the same as ComputeIntegrityHashWithOptions, and WithCRC32C enables
+// the CRC32C digest.
+func ComputePayloadDigests(ctx context.Context, reader io.Reader, segmentSize int64, dataShards, parityShards int,
+	opts ...Option,
+) (*PayloadDigests, error) {
+	o := newHashOptions(opts...)
+	md5Hash := md5.New()
+	sha256Hash := sha256.New()
+	writers := []io.Writer{md5Hash, sha256Hash}
+	var crcWriter *crc32cWriter
+	if o.crc32c {
+		crcWriter = &crc32cWriter{}
+		writers = append(writers, crcWriter)
+	}
+
+	// the payload is read sequentially by both the serial and the parallel ways, so the digests see the data in order
+	meta, err := ComputeIntegrityMeta(ctx, io.TeeReader(reader, io.MultiWriter(writers...)), segmentSize, dataShards,
+		parityShards, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	digests := &PayloadDigests{
+		Meta:   meta,
+		MD5:    md5Hash.Sum(nil),
+		SHA256: sha256Hash.Sum(nil),
+	}
+	if crcWriter != nil {
+		digests.CRC32C = binary.BigEndian.AppendUint32(nil, crcWriter.crc)
+	}
+	return digests, nil
+}
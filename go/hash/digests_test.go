@@ -0,0 +1,71 @@
+package hash
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"hash/crc32"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-common/go/redundancy"
+)
+
+func TestComputePayloadDigests(t *testing.T) {
+	content := newTestContent(testSegmentSize*3 + 999)
+	expectedMeta, err := ComputeIntegrityMetaFromBuffer(content, testSegmentSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks)
+	require.NoError(t, err)
+	md5Sum := md5.Sum(content)
+	sha256Sum := sha256.Sum256(content)
+	crc32c := crc32.Checksum(content, crc32.MakeTable(crc32.Castagnoli))
+
+	for _, c := range []struct {
+		name string
+		opts []Option
+		// the CRC32C is only computed with WithCRC32C
+		withCRC32C bool
+	}{
+		{"serial", []Option{WithSerial(true)}, false},
+		{"parallel", []Option{WithSerial(false)}, false},
+		{"CRC32C", []Option{WithCRC32C()}, true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			digests, err := ComputePayloadDigests(context.Background(), bytes.NewReader(content), testSegmentSize,
+				redundancy.DataBlocks, redundancy.ParityBlocks, c.opts...)
+			require.NoError(t, err)
+			if !reflect.DeepEqual(expectedMeta, digests.Meta) {
+				t.Errorf("meta mismatch, expected %+v, got %+v", expectedMeta, digests.Meta)
+			}
+			if !bytes.Equal(md5Sum[:], digests.MD5) {
+				t.Errorf("MD5 %x, expected %x", digests.MD5, md5Sum)
+			}
+			if !bytes.Equal(sha256Sum[:], digests.SHA256) {
+				t.Errorf("SHA256 %x, expected %x", digests.SHA256, sha256Sum)
+			}
+			if expected := base64.StdEncoding.EncodeToString(md5Sum[:]); digests.ContentMD5() != expected {
+				t.Errorf("ContentMD5 %s, expected %s", digests.ContentMD5(), expected)
+			}
+			if expected := hex.EncodeToString(sha256Sum[:]); digests.ContentSHA256() != expected {
+				t.Errorf("ContentSHA256 %s, expected %s", digests.ContentSHA256(), expected)
+			}
+
+			if !c.withCRC32C {
+				if digests.CRC32C != nil {
+					t.Errorf("CRC32C %x computed without WithCRC32C", digests.CRC32C)
+				}
+				return
+			}
+			require.Len(t, digests.CRC32C, 4)
+			if crc := binary.BigEndian.Uint32(digests.CRC32C); crc != crc32c {
+				t.Errorf("CRC32C %08x, expected %08x", crc, crc32c)
+			}
+		})
+	}
+}
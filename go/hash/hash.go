@@ -2,19 +2,22 @@ package hash
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
-	"os"
-	"runtime"
 	"sync"
-
-	"github.com/rs/zerolog/log"
+	"time"
 
 	"github.com/bnb-chain/greenfield-common/go/redundancy"
 	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
 )
 
+// ErrUnsupportedRedundancyType indicates the redundancy type is neither EC nor replica
+var ErrUnsupportedRedundancyType = errors.New("unsupported redundancy type")
+
+// the default limits of the parallel computing, which can be changed by Option
 const (
 	maxThreadNum   = 5
 	jobChannelSize = 100
@@ -29,15 +32,40 @@ type IntegrityHasher struct {
 	dataShards   int
 	parityShards int
 	contentLen   int64
+	// redundancyType decides the way to compute the hashes of pieces
+	redundancyType storagetypes.RedundancyType
+	observer       Observer
 }
 
 func NewHasher(size int64, data, parity int) *IntegrityHasher {
+	return NewHasherWithRedundancyType(size, data, parity, storagetypes.REDUNDANCY_EC_TYPE)
+}
+
+// NewHasherWithRedundancyType creates an IntegrityHasher of the redundancy type, in the replica type, every one of
+// the data+parity replicas stores the whole segments
+func NewHasherWithRedundancyType(size int64, data, parity int, redundancyType storagetypes.RedundancyType) *IntegrityHasher {
 	return &IntegrityHasher{
-		buffer:       make([]byte, 0),
-		segmentSize:  size,
-		dataShards:   data,
-		parityShards: parity,
+		buffer:         make([]byte, 0),
+		segmentSize:    size,
+		dataShards:     data,
+		parityShards:   parity,
+		redundancyType: redundancyType,
+		observer:       NopObserver{},
+	}
+}
+
+// SetObserver sets the observer which receives the progress of the hashing, the segment IDs are counted from the
+// first segment appended
+func (i *IntegrityHasher) SetObserver(observer Observer) {
+	i.observer = observer
+}
+
+// getObserver returns the observer, NopObserver is returned if it is not set
+func (i *IntegrityHasher) getObserver() Observer {
+	if i.observer == nil {
+		return NopObserver{}
 	}
+	return i.observer
 }
 
 // Init the integrityHash fields
@@ -56,99 +84,114 @@ func (i *IntegrityHasher) Init() {
 	i.contentLen = 0
 }
 
-// Append the data chunks to IntegrityHasher , the data size should be less than segment size
+// Append the data chunks to IntegrityHasher, the data can be of any size and will be split by segment size
 func (i *IntegrityHasher) Append(data []byte) error {
-	dataSize := len(data)
-	if dataSize > int(i.segmentSize) {
-		return errors.New("the length of data size should be less than segmentSize")
-	}
-	if len(i.buffer) >= int(i.segmentSize) {
-		return errors.New("the buffer of handler should be less than segmentSize")
-	}
-	originBuffer := make([]byte, len(i.buffer))
-	copy(originBuffer, i.buffer)
-	// use tempBuffer to store exceed data
-	var tempBuffer []byte
-	totalSize := int64(dataSize + len(i.buffer))
-	if totalSize > i.segmentSize {
-		index := dataSize - int(totalSize-i.segmentSize)
-		tempBuffer = make([]byte, dataSize-index)
-		copy(tempBuffer, data[index:])
-		// buffer should be equal with segment size
-		i.buffer = append(i.buffer, data[:index]...)
-	} else {
-		i.buffer = append(i.buffer, data...)
-		// if buffer size less than segment size, just store the data
-		if totalSize < i.segmentSize {
-			return nil
+	_, err := i.Write(data)
+	return err
+}
+
+// Write implements io.Writer, the data is buffered and hashed once a whole segment is collected
+func (i *IntegrityHasher) Write(data []byte) (int, error) {
+	if i.segmentSize <= 0 {
+		return 0, ErrInvalidSegmentSize
+	}
+	written := 0
+	if len(data) > 0 && int64(cap(i.buffer)) < i.segmentSize {
+		i.buffer = append(make([]byte, 0, i.segmentSize), i.buffer...)
+	}
+	for len(data) > 0 {
+		n := int(i.segmentSize) - len(i.buffer)
+		if n > len(data) {
+			n = len(data)
 		}
+		i.buffer = append(i.buffer, data[:n]...)
+		if int64(len(i.buffer)) == i.segmentSize {
+			if err := i.computeSegmentHash(i.buffer); err != nil {
+				// drop the data which has not been hashed
+				i.buffer = i.buffer[:len(i.buffer)-n]
+				return written, err
+			}
+			i.buffer = i.buffer[:0]
+		}
+		data = data[n:]
+		written += n
 	}
+	return written, nil
+}
 
-	// compute segment hash
-	if err := i.computeBufferHash(); err != nil {
-		return err
+// ReadFrom implements io.ReaderFrom, it reads data from reader until EOF and hashes the segments,
+// the data not enough for a segment is kept in the buffer
+func (i *IntegrityHasher) ReadFrom(reader io.Reader) (int64, error) {
+	if i.segmentSize <= 0 {
+		return 0, ErrInvalidSegmentSize
 	}
-
-	// copy exceed data to buffer if exist
-	if len(tempBuffer) > 0 {
-		i.buffer = i.buffer[:0]
-		i.buffer = append(i.buffer, tempBuffer...)
-		return nil
+	buffered := int64(len(i.buffer))
+	segReader := NewSegmentReader(io.MultiReader(bytes.NewReader(i.buffer), reader), i.segmentSize)
+	// the segments are read into the pooled buffer, the buffered data has been consumed by the first read
+	segBuffer := getSegmentBuffer(i.segmentSize)
+	defer putSegmentBuffer(segBuffer)
+	total := int64(0)
+	for {
+		seg, err := segReader.NextInto(*segBuffer)
+		total += int64(len(seg.Data))
+		if err == io.EOF {
+			return total - buffered, nil
+		}
+		if err == nil && int64(len(seg.Data)) == i.segmentSize {
+			err = i.computeSegmentHash(seg.Data)
+			if err == nil {
+				i.buffer = i.buffer[:0]
+				continue
+			}
+		}
+		// keep the data which has not been hashed, the last segment which is not full waits for the following data
+		i.buffer = append(i.buffer[:0], seg.Data...)
+		if err != nil {
+			return total - buffered, err
+		}
 	}
-
-	i.buffer = i.buffer[:0]
-	return nil
 }
 
 // Finish return the result of the Integrity hashes
 func (i *IntegrityHasher) Finish() ([][]byte, int64, storagetypes.RedundancyType, error) {
+	return integrityHashResult(i.FinishMeta())
+}
+
+// FinishMeta return the integrity hashes with the checksum lists of segments and pieces
+func (i *IntegrityHasher) FinishMeta() (*IntegrityMeta, error) {
 	// deal with  remain content tot be computed
 	if len(i.buffer) > 0 {
-		if err := i.computeBufferHash(); err != nil {
-			return nil, 0, storagetypes.REDUNDANCY_EC_TYPE, err
+		if err := i.computeSegmentHash(i.buffer); err != nil {
+			return nil, err
 		}
+		i.buffer = i.buffer[:0]
 	}
 
-	hashList := make([][]byte, i.parityShards+i.dataShards+1)
-
-	hashList[0] = GenerateIntegrityHash(i.segHashes)
-
-	wg := &sync.WaitGroup{}
-	spLen := len(i.ecDataHashes)
-	wg.Add(spLen)
-	for spID, content := range i.ecDataHashes {
-		go func(data [][]byte, id int) {
-			defer wg.Done()
-			hashList[id+1] = GenerateIntegrityHash(data)
-		}(content, spID)
+	// copy the checksum lists since the hasher may be used to append data
+	segHashes := append([][]byte{}, i.segHashes...)
+	ecDataHashes := make([][][]byte, len(i.ecDataHashes))
+	for index, pieceHashes := range i.ecDataHashes {
+		ecDataHashes[index] = append([][]byte{}, pieceHashes...)
 	}
-	wg.Wait()
-
-	return hashList, i.contentLen, storagetypes.REDUNDANCY_EC_TYPE, nil
+	i.getObserver().OnFinish(i.contentLen)
+	return newIntegrityMeta(segHashes, ecDataHashes, i.contentLen, i.redundancyType), nil
 }
 
-// computeBufferHash erasure encode the buffer of IntegrityHasher and compute the hash
-func (i *IntegrityHasher) computeBufferHash() error {
-	i.contentLen += int64(len(i.buffer))
-	originBuffer := make([]byte, len(i.buffer))
-	copy(originBuffer, i.buffer)
-	// compute segment hash
-	checksum := GenerateChecksum(i.buffer)
-	i.segHashes = append(i.segHashes, checksum)
-	// get erasure encoded bytes and compute pieces hashes
-	encodeShards, err := redundancy.EncodeRawSegment(i.buffer, i.dataShards, i.parityShards)
+// computeSegmentHash erasure encode the segment data and compute the hash
+func (i *IntegrityHasher) computeSegmentHash(data []byte) error {
+	seg := SegmentInfo{SegmentID: len(i.segHashes), Data: data}
+	i.getObserver().OnSegmentRead(seg.SegmentID, len(data))
+	checksum, pieceChecksumList, err := computeSegmentHashes(seg, i.dataShards, i.parityShards, i.redundancyType,
+		i.getObserver())
 	if err != nil {
-		// recover buffer content if encode error
-		i.buffer = i.buffer[:0]
-		i.buffer = append(i.buffer, originBuffer...)
 		return err
 	}
 
-	for index, shard := range encodeShards {
-		// compute hash of pieces
-		piecesHash := GenerateChecksum(shard)
+	i.segHashes = append(i.segHashes, checksum)
+	for index, piecesHash := range pieceChecksumList {
 		i.ecDataHashes[index] = append(i.ecDataHashes[index], piecesHash)
 	}
+	i.contentLen += int64(len(data))
 
 	return nil
 }
@@ -159,10 +202,25 @@ func (i *IntegrityHasher) computeBufferHash() error {
 func ComputeIntegrityHash(reader io.Reader, segmentSize int64, dataShards, parityShards int, isSerial bool) ([][]byte,
 	int64, storagetypes.RedundancyType, error,
 ) {
-	if isSerial {
-		return ComputeIntegrityHashSerial(reader, segmentSize, dataShards, parityShards)
-	}
-	return ComputeIntegrityHashParallel(reader, segmentSize, dataShards, parityShards)
+	return ComputeIntegrityHashWithContext(context.Background(), reader, segmentSize, dataShards, parityShards, isSerial)
+}
+
+// ComputeIntegrityHashWithContext is the same as ComputeIntegrityHash, the computing stops and returns ctx.Err()
+// once the ctx is done
+func ComputeIntegrityHashWithContext(ctx context.Context, reader io.Reader, segmentSize int64, dataShards, parityShards int,
+	isSerial bool,
+) ([][]byte, int64, storagetypes.RedundancyType, error) {
+	return ComputeIntegrityHashWithOptions(ctx, reader, segmentSize, dataShards, parityShards, WithSerial(isSerial))
+}
+
+// ComputeIntegrityHashWithOptions split the reader into segment, ec encode the data, compute the hash roots of pieces
+// return the hash result array list and data size.
+// The opts configure the serial or parallel way, the worker number, the in-flight segments and the logger.
+// The computing stops and returns ctx.Err() once the ctx is done.
+func ComputeIntegrityHashWithOptions(ctx context.Context, reader io.Reader, segmentSize int64, dataShards,
+	parityShards int, opts ...Option,
+) ([][]byte, int64, storagetypes.RedundancyType, error) {
+	return integrityHashResult(ComputeIntegrityMeta(ctx, reader, segmentSize, dataShards, parityShards, opts...))
 }
 
 // ComputeIntegrityHashSerial split the reader into segment, ec encode the data, compute the hash roots of pieces in a serial way
@@ -170,6 +228,21 @@ func ComputeIntegrityHash(reader io.Reader, segmentSize int64, dataShards, parit
 func ComputeIntegrityHashSerial(reader io.Reader, segmentSize int64, dataShards, parityShards int) ([][]byte, int64,
 	storagetypes.RedundancyType, error,
 ) {
+	return ComputeIntegrityHashSerialWithContext(context.Background(), reader, segmentSize, dataShards, parityShards)
+}
+
+// ComputeIntegrityHashSerialWithContext is the same as ComputeIntegrityHashSerial, the reading stops and returns
+// ctx.Err() once the ctx is done
+func ComputeIntegrityHashSerialWithContext(ctx context.Context, reader io.Reader, segmentSize int64, dataShards,
+	parityShards int,
+) ([][]byte, int64, storagetypes.RedundancyType, error) {
+	return ComputeIntegrityHashWithOptions(ctx, reader, segmentSize, dataShards, parityShards, WithSerial(true))
+}
+
+// computeIntegrityHashSerial read the segments and compute the hashes one by one
+func computeIntegrityHashSerial(ctx context.Context, reader io.Reader, segmentSize int64, dataShards, parityShards int,
+	o *hashOptions,
+) (*IntegrityMeta, error) {
 	var segChecksumList [][]byte
 	ecShards := dataShards + parityShards
 
@@ -178,80 +251,58 @@ func ComputeIntegrityHashSerial(reader io.Reader, segmentSize int64, dataShards,
 		encodeDataHash[i] = make([][]byte, 0)
 	}
 
-	hashList := make([][]byte, ecShards+1)
+	if segmentSize <= 0 {
+		return nil, ErrInvalidSegmentSize
+	}
 	contentLen := int64(0)
-	// read the data by segment segmentSize
+	// read the data by segment segmentSize, the segment buffer is reused since the hashes do not refer to it
+	segReader := NewSegmentReader(reader, segmentSize)
+	segBuffer := getSegmentBuffer(segmentSize)
+	defer putSegmentBuffer(segBuffer)
 	for {
-		seg := make([]byte, segmentSize)
-		n, err := reader.Read(seg)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		seg, err := segReader.NextInto(*segBuffer)
 		if err != nil {
 			if err != io.EOF {
-				log.Error().Msg("failed to read content:" + err.Error())
-				return nil, 0, storagetypes.REDUNDANCY_EC_TYPE, err
+				o.logger.Error().Msg("failed to read content:" + err.Error())
+				return nil, err
 			}
 			break
 		}
 
-		if n > 0 && n <= int(segmentSize) {
-			contentLen += int64(n)
-			data := seg[:n]
-			// compute segment hash
-			checksum := GenerateChecksum(data)
-			segChecksumList = append(segChecksumList, checksum)
-
-			if err = encodeAndComputeHash(encodeDataHash, data, dataShards, parityShards); err != nil {
-				return nil, 0, storagetypes.REDUNDANCY_EC_TYPE, err
-			}
+		contentLen += int64(len(seg.Data))
+		o.observer.OnSegmentRead(seg.SegmentID, len(seg.Data))
+		checksum, pieceChecksumList, err := computeSegmentHashes(seg, dataShards, parityShards, o.redundancyType,
+			o.observer)
+		if err != nil {
+			return nil, err
+		}
+		segChecksumList = append(segChecksumList, checksum)
+		for index, piecesHash := range pieceChecksumList {
+			encodeDataHash[index] = append(encodeDataHash[index], piecesHash)
 		}
 	}
 
-	// combine the hash root of pieces of the PrimarySP
-	hashList[0] = GenerateIntegrityHash(segChecksumList)
-
-	// compute the integrity hash of the SecondarySP
-	wg := &sync.WaitGroup{}
-	spLen := len(encodeDataHash)
-	wg.Add(spLen)
-	for spID, content := range encodeDataHash {
-		go func(data [][]byte, id int) {
-			defer wg.Done()
-			hashList[id+1] = GenerateIntegrityHash(data)
-		}(content, spID)
-	}
-
-	wg.Wait()
-
-	return hashList, contentLen, storagetypes.REDUNDANCY_EC_TYPE, nil
-}
-
-func encodeAndComputeHash(encodeDataHash [][][]byte, segment []byte, dataShards, parityShards int) error {
-	// get erasure encode bytes
-	encodeShards, err := redundancy.EncodeRawSegment(segment, dataShards, parityShards)
-	if err != nil {
-		return err
-	}
-
-	for index, shard := range encodeShards {
-		// compute hash of pieces
-		piecesHash := GenerateChecksum(shard)
-		encodeDataHash[index] = append(encodeDataHash[index], piecesHash)
-	}
-
-	return nil
+	o.observer.OnFinish(contentLen)
+	return newIntegrityMeta(segChecksumList, encodeDataHash, contentLen, o.redundancyType), nil
 }
 
 // ComputerHashFromFile open a local file and compute hash result and segmentSize
 func ComputerHashFromFile(filePath string, segmentSize int64, dataShards, parityShards int) ([][]byte, int64,
 	storagetypes.RedundancyType, error,
 ) {
-	f, err := os.Open(filePath)
-	if err != nil {
-		log.Error().Msg("failed to open file:" + err.Error())
-		return nil, 0, storagetypes.REDUNDANCY_EC_TYPE, err
-	}
-	defer f.Close()
+	return ComputerHashFromFileWithContext(context.Background(), filePath, segmentSize, dataShards, parityShards)
+}
 
-	return ComputeIntegrityHash(f, segmentSize, dataShards, parityShards, false)
+// ComputerHashFromFileWithContext is the same as ComputerHashFromFile, the computing stops and returns ctx.Err()
+// once the ctx is done, the opts configure the way to compute the hash.
+// The segments of a regular file are read and hashed in parallel by ComputeIntegrityHashFromReaderAt.
+func ComputerHashFromFileWithContext(ctx context.Context, filePath string, segmentSize int64, dataShards,
+	parityShards int, opts ...Option,
+) ([][]byte, int64, storagetypes.RedundancyType, error) {
+	return integrityHashResult(ComputeIntegrityMetaFromFile(ctx, filePath, segmentSize, dataShards, parityShards, opts...))
 }
 
 // ComputerHashFromBuffer support computing hash and segmentSize from byte buffer
@@ -262,41 +313,118 @@ func ComputerHashFromBuffer(content []byte, segmentSize int64, dataShards, parit
 	return ComputeIntegrityHash(reader, segmentSize, dataShards, parityShards, false)
 }
 
-// computePieceHashes encode the segment and return the hashes of ec pieces
-func computePieceHashes(segment []byte, dataShards, parityShards int) ([][]byte, error) {
-	// get erasure encode bytes
-	encodeShards, err := redundancy.EncodeRawSegment(segment, dataShards, parityShards)
+// computeSegmentHashes return the checksum of the segment and the hashes of its pieces, the observer is notified
+// after the segment is hashed
+func computeSegmentHashes(seg SegmentInfo, dataShards, parityShards int, redundancyType storagetypes.RedundancyType,
+	observer Observer,
+) ([]byte, [][]byte, error) {
+	start := time.Now()
+	checksum := GenerateChecksum(seg.Data)
+	pieceChecksumList, err := computePieceHashes(seg, checksum, dataShards, parityShards, redundancyType, observer)
+	if err != nil {
+		return nil, nil, err
+	}
+	observer.OnSegmentHashed(seg.SegmentID, time.Since(start))
+	return checksum, pieceChecksumList, nil
+}
+
+// computePieceHashes return the hashes of the pieces of each redundancy index. In the EC type, the segment is erasure
+// encoded into ec pieces, in the replica type, every replica stores the whole segment of segChecksum.
+func computePieceHashes(seg SegmentInfo, segChecksum []byte, dataShards, parityShards int,
+	redundancyType storagetypes.RedundancyType, observer redundancy.EncodeObserver,
+) ([][]byte, error) {
+	switch redundancyType {
+	case storagetypes.REDUNDANCY_EC_TYPE:
+	case storagetypes.REDUNDANCY_REPLICA_TYPE:
+		pieceChecksumList := make([][]byte, dataShards+parityShards)
+		for index := range pieceChecksumList {
+			pieceChecksumList[index] = segChecksum
+		}
+		return pieceChecksumList, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedRedundancyType, redundancyType)
+	}
+
+	// get erasure encode bytes, the shards are encoded into the buffer of the pooled encoder
+	encoder, err := getSegmentEncoder(dataShards, parityShards)
+	if err != nil {
+		return nil, err
+	}
+	defer putSegmentEncoder(encoder, dataShards, parityShards)
+	start := time.Now()
+	encodeShards, err := encoder.Encode(seg.Data)
 	if err != nil {
 		return nil, err
 	}
+	observer.OnSegmentEncoded(seg.SegmentID, time.Since(start))
 
-	var pieceChecksumList [][]byte
-	for _, shard := range encodeShards {
-		// compute hash of pieces
-		piecesHash := GenerateChecksum(shard)
-		pieceChecksumList = append(pieceChecksumList, piecesHash)
+	// compute hash of pieces, the checksums of pieces share one allocation
+	checksums := make([]byte, len(encodeShards)*sha256.Size)
+	pieceChecksumList := make([][]byte, len(encodeShards))
+	for index := range pieceChecksumList {
+		pieceChecksumList[index] = checksums[index*sha256.Size : (index+1)*sha256.Size : (index+1)*sha256.Size]
 	}
+	sumChecksums(encodeShards, pieceChecksumList)
 
 	return pieceChecksumList, nil
 }
 
+// generateIntegrityHashList compute the integrity hash of the segments as the first one,
+// and the integrity hashes of the ec pieces of each SecondarySP in order
+func generateIntegrityHashList(segChecksumList [][]byte, encodeDataHash [][][]byte) [][]byte {
+	hashList := make([][]byte, len(encodeDataHash)+1)
+	// combine the hash root of pieces of the PrimarySP
+	hashList[0] = GenerateIntegrityHash(segChecksumList)
+
+	// compute the integrity hash of the SecondarySPs
+	wg := &sync.WaitGroup{}
+	wg.Add(len(encodeDataHash))
+	for spID, content := range encodeDataHash {
+		go func(data [][]byte, id int) {
+			defer wg.Done()
+			hashList[id+1] = GenerateIntegrityHash(data)
+		}(content, spID)
+	}
+	wg.Wait()
+
+	return hashList
+}
+
+// segmentJob is the segment to be hashed by hashWorker, the buffer of the segment data is put back to the pool
+// once the segment is handled
+type segmentJob struct {
+	SegmentInfo
+	buffer *[]byte
+}
+
 // hashWorker receive the segment info and compute the corresponding segment hash and piece hashes.
 // The result will be stored in the sync map to compute integrity hash in order.
-func hashWorker(jobs <-chan SegmentInfo, errChan chan<- error, dataShards, parityShards int, wg *sync.WaitGroup,
+// If the ctx is done, the remaining jobs are drained without computing, if the computing fails, the error is sent to
+// errChan and the ctx is canceled to stop the other workers.
+// A token of inflight is released once a segment is handled.
+func hashWorker(ctx context.Context, cancel context.CancelFunc, jobs <-chan segmentJob, inflight <-chan struct{},
+	errChan chan<- error, dataShards, parityShards int, o *hashOptions, wg *sync.WaitGroup,
 	segmentHashMap *sync.Map, pieceHashMap *sync.Map,
 ) {
 	defer wg.Done()
 
 	for segInfo := range jobs {
-		checksum := GenerateChecksum(segInfo.Data)
-		segmentHashMap.Store(segInfo.SegmentID, checksum)
-
-		pieceChecksumList, err := computePieceHashes(segInfo.Data, dataShards, parityShards)
-		if err != nil {
-			errChan <- err
-			return
+		if ctx.Err() == nil {
+			checksum, pieceChecksumList, err := computeSegmentHashes(segInfo.SegmentInfo, dataShards, parityShards,
+				o.redundancyType, o.observer)
+			if err != nil {
+				select {
+				case errChan <- err:
+				default:
+				}
+				cancel()
+			} else {
+				segmentHashMap.Store(segInfo.SegmentID, checksum)
+				pieceHashMap.Store(segInfo.SegmentID, pieceChecksumList)
+			}
 		}
-		pieceHashMap.Store(segInfo.SegmentID, pieceChecksumList)
+		putSegmentBuffer(segInfo.buffer)
+		<-inflight
 	}
 }
 
@@ -305,52 +433,76 @@ func hashWorker(jobs <-chan SegmentInfo, errChan chan<- error, dataShards, parit
 func ComputeIntegrityHashParallel(reader io.Reader, segmentSize int64, dataShards, parityShards int) ([][]byte, int64,
 	storagetypes.RedundancyType, error,
 ) {
+	return ComputeIntegrityHashParallelWithContext(context.Background(), reader, segmentSize, dataShards, parityShards)
+}
+
+// ComputeIntegrityHashParallelWithContext is the same as ComputeIntegrityHashParallel, once the ctx is done, the reading
+// stops, the hash workers exit and ctx.Err() is returned
+func ComputeIntegrityHashParallelWithContext(ctx context.Context, reader io.Reader, segmentSize int64, dataShards,
+	parityShards int,
+) ([][]byte, int64, storagetypes.RedundancyType, error) {
+	return ComputeIntegrityHashWithOptions(ctx, reader, segmentSize, dataShards, parityShards, WithSerial(false))
+}
+
+// computeIntegrityHashParallel read the segments and dispatch them to the hash workers
+func computeIntegrityHashParallel(ctx context.Context, reader io.Reader, segmentSize int64, dataShards, parityShards int,
+	o *hashOptions,
+) (*IntegrityMeta, error) {
+	if segmentSize <= 0 {
+		return nil, ErrInvalidSegmentSize
+	}
 	var (
 		segChecksumList [][]byte
 		ecShards        = dataShards + parityShards
 		contentLen      = int64(0)
 		wg              sync.WaitGroup
+		readErr         error
 	)
+	// the workers cancel the ctx to stop reading if failed to compute hash
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// use sync.map to store the corresponding data of intermediate hash results and segment IDs
 	segHashMap := &sync.Map{}
 	pieceHashMap := &sync.Map{}
 	encodeDataHash := make([][][]byte, ecShards)
-	// store the result of integrity hash
-	hashList := make([][]byte, ecShards+1)
 
-	jobChan := make(chan SegmentInfo, jobChannelSize)
+	// the reading is blocked once the number of in-flight segments reaches the limit
+	inflightNum := o.inflightSegments(segmentSize)
+	inflight := make(chan struct{}, inflightNum)
+	jobChan := make(chan segmentJob, inflightNum)
 	errChan := make(chan error, 1)
-	// the thread num should be less than maxThreadNum
-	threadNum := runtime.NumCPU() / 2
-	if threadNum > maxThreadNum {
-		threadNum = maxThreadNum
-	}
 	// start workers to compute hash of each segment
-	for i := 0; i < threadNum; i++ {
+	for i := 0; i < o.workerNum; i++ {
 		wg.Add(1)
-		go hashWorker(jobChan, errChan, dataShards, parityShards, &wg, segHashMap, pieceHashMap)
+		go hashWorker(ctx, cancel, jobChan, inflight, errChan, dataShards, parityShards, o, &wg, segHashMap, pieceHashMap)
 	}
 
 	jobNum := 0
+	segReader := NewSegmentReader(reader, segmentSize)
+readLoop:
 	for {
-		seg := make([]byte, segmentSize)
-		n, err := reader.Read(seg)
+		select {
+		case inflight <- struct{}{}:
+		case <-ctx.Done():
+			break readLoop
+		}
+		segBuffer := getSegmentBuffer(segmentSize)
+		seg, err := segReader.NextInto(*segBuffer)
 		if err != nil {
+			putSegmentBuffer(segBuffer)
+			<-inflight
 			if err != io.EOF {
-				log.Error().Msg("failed to read content:" + err.Error())
-				return nil, 0, storagetypes.REDUNDANCY_EC_TYPE, err
+				readErr = err
 			}
 			break
 		}
 
-		if n > 0 && n <= int(segmentSize) {
-			contentLen += int64(n)
-			data := seg[:n]
-			// compute segment hash
-
-			jobChan <- SegmentInfo{SegmentID: jobNum, Data: data}
-			jobNum++
-		}
+		contentLen += int64(len(seg.Data))
+		o.observer.OnSegmentRead(seg.SegmentID, len(seg.Data))
+		// the job channel never blocks since its capacity is the same as the in-flight limit
+		jobChan <- segmentJob{SegmentInfo: seg, buffer: segBuffer}
+		jobNum++
 	}
 	close(jobChan)
 
@@ -361,24 +513,33 @@ func ComputeIntegrityHashParallel(reader io.Reader, segmentSize int64, dataShard
 	wg.Wait()
 	close(errChan)
 
+	if readErr != nil {
+		o.logger.Error().Msg("failed to read content:" + readErr.Error())
+		return nil, readErr
+	}
+
 	// check error
 	for err := range errChan {
 		if err != nil {
-			log.Error().Msg("err chan detected err:" + err.Error())
-			return nil, 0, storagetypes.REDUNDANCY_EC_TYPE, err
+			o.logger.Error().Msg("err chan detected err:" + err.Error())
+			return nil, err
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	for i := 0; i < jobNum; i++ {
 		segHashValue, ok := segHashMap.Load(i)
 		if !ok {
-			return nil, 0, storagetypes.REDUNDANCY_EC_TYPE, fmt.Errorf("fail to load the segment hash")
+			return nil, fmt.Errorf("fail to load the segment hash")
 		}
 		segChecksumList = append(segChecksumList, segHashValue.([]byte))
 
 		pieceHashValue, ok := pieceHashMap.Load(i)
 		if !ok {
-			return nil, 0, storagetypes.REDUNDANCY_EC_TYPE, fmt.Errorf("fail to load the segment hash")
+			return nil, fmt.Errorf("fail to load the segment hash")
 		}
 		hashValues := pieceHashValue.([][]byte)
 		for j := 0; j < len(encodeDataHash); j++ {
@@ -386,19 +547,14 @@ func ComputeIntegrityHashParallel(reader io.Reader, segmentSize int64, dataShard
 		}
 	}
 
-	//  compute the integrity root of pieces of the PrimarySP
-	hashList[0] = GenerateIntegrityHash(segChecksumList)
+	o.observer.OnFinish(contentLen)
+	return newIntegrityMeta(segChecksumList, encodeDataHash, contentLen, o.redundancyType), nil
+}
 
-	// compute the integrity hash of the SecondarySPs
-	spLen := len(encodeDataHash)
-	wg.Add(spLen)
-	for spID, content := range encodeDataHash {
-		go func(data [][]byte, id int) {
-			defer wg.Done()
-			hashList[id+1] = GenerateIntegrityHash(data)
-		}(content, spID)
+// checkRedundancyType returns ErrUnsupportedRedundancyType if the redundancy type is neither EC nor replica
+func checkRedundancyType(redundancyType storagetypes.RedundancyType) error {
+	if redundancyType != storagetypes.REDUNDANCY_EC_TYPE && redundancyType != storagetypes.REDUNDANCY_REPLICA_TYPE {
+		return fmt.Errorf("%w: %s", ErrUnsupportedRedundancyType, redundancyType)
 	}
-
-	wg.Wait()
-	return hashList, contentLen, storagetypes.REDUNDANCY_EC_TYPE, nil
+	return nil
 }
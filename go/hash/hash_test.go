@@ -2,16 +2,23 @@ package hash
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
 	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/bnb-chain/greenfield-common/go/redundancy"
 	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
@@ -276,3 +283,333 @@ func verifyHashResult(hashHandler *IntegrityHasher, expectedResult []string, exp
 
 	return nil
 }
+
+// testSegmentSize is the segment size of the tests with the small contents, so the contents have many segments
+const testSegmentSize = 16 * 1024
+
+// newTestContent returns the random content of size
+func newTestContent(size int) []byte {
+	content := make([]byte, size)
+	rand.Read(content)
+	return content
+}
+
+// serialIntegrityHash returns the integrity hashes and the size of content computed by ComputeIntegrityHashSerial
+// with testSegmentSize, which are the expected results of the other ways of hashing
+func serialIntegrityHash(t *testing.T, content []byte) ([][]byte, int64) {
+	t.Helper()
+	hashList, size, _, err := ComputeIntegrityHashSerial(bytes.NewReader(content), testSegmentSize,
+		redundancy.DataBlocks, redundancy.ParityBlocks)
+	require.NoError(t, err)
+	return hashList, size
+}
+
+// checkHasherResult finishes the hasher and compares its result with the expected one
+func checkHasherResult(t *testing.T, hasher *IntegrityHasher, expectedHashList [][]byte, expectedSize int64) {
+	t.Helper()
+	hashList, size, _, err := hasher.Finish()
+	require.NoError(t, err)
+	if size != expectedSize {
+		t.Errorf("content length %d, expected %d", size, expectedSize)
+	}
+	checkHashList(t, expectedHashList, hashList)
+}
+
+// checkHashList reports the hash list which is not the same as the expected one
+func checkHashList(t *testing.T, expected, hashList [][]byte) {
+	t.Helper()
+	if !equalChecksums(expected, hashList) {
+		t.Errorf("hash list mismatch, expected %x, got %x", expected, hashList)
+	}
+}
+
+func equalChecksums(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// TestIntegrityHasherWriter compare the result of IntegrityHasher used as io.Writer and io.ReaderFrom
+// with the serial version, the data is written by chunks of different size
+func TestIntegrityHasherWriter(t *testing.T) {
+	for _, size := range []int{0, 100, testSegmentSize, testSegmentSize*3 + 1, testSegmentSize*5 - 7} {
+		content := newTestContent(size)
+		expectedHashList, expectedSize := serialIntegrityHash(t, content)
+
+		for _, c := range []struct {
+			name  string
+			write func(hasher *IntegrityHasher) error
+		}{
+			// io.Copy will use the ReadFrom method of IntegrityHasher
+			{"ReadFrom", func(hasher *IntegrityHasher) error {
+				n, err := io.Copy(hasher, bytes.NewReader(content))
+				if err == nil && n != int64(size) {
+					return fmt.Errorf("copied %d bytes, expected %d", n, size)
+				}
+				return err
+			}},
+			// write the data by chunks of random size, which may be larger than segment size
+			{"Write", func(hasher *IntegrityHasher) error {
+				for remain := content; len(remain) > 0; {
+					chunkSize := rand.Intn(testSegmentSize*3) + 1
+					if chunkSize > len(remain) {
+						chunkSize = len(remain)
+					}
+					n, err := hasher.Write(remain[:chunkSize])
+					if err != nil {
+						return err
+					}
+					if n != chunkSize {
+						return fmt.Errorf("wrote %d bytes, expected %d", n, chunkSize)
+					}
+					remain = remain[chunkSize:]
+				}
+				return nil
+			}},
+			// Append should accept the data larger than segment size
+			{"Append", func(hasher *IntegrityHasher) error {
+				return hasher.Append(content)
+			}},
+		} {
+			t.Run(fmt.Sprintf("%s %d bytes", c.name, size), func(t *testing.T) {
+				hasher := NewHasher(testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+				hasher.Init()
+				require.NoError(t, c.write(hasher))
+				checkHasherResult(t, hasher, expectedHashList, expectedSize)
+			})
+		}
+	}
+}
+
+// TestIntegrityHasherInvalidSegmentSize checks the hasher of the non-positive segment size rejects the data
+// instead of looping forever
+func TestIntegrityHasherInvalidSegmentSize(t *testing.T) {
+	for _, segmentSize := range []int64{0, -1} {
+		hasher := NewHasher(segmentSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+		hasher.Init()
+		n, err := hasher.Write([]byte("data"))
+		if !errors.Is(err, ErrInvalidSegmentSize) || n != 0 {
+			t.Errorf("segment size %d: Write returned (%d, %v), expected %v", segmentSize, n, err,
+				ErrInvalidSegmentSize)
+		}
+		if err := hasher.Append([]byte("data")); !errors.Is(err, ErrInvalidSegmentSize) {
+			t.Errorf("segment size %d: Append returned %v, expected %v", segmentSize, err, ErrInvalidSegmentSize)
+		}
+	}
+}
+
+// TestIntegrityHasherMarshal restore the state of IntegrityHasher in the middle of writing, the restored hasher
+// should produce the same result as the serial version
+func TestIntegrityHasherMarshal(t *testing.T) {
+	content := newTestContent(testSegmentSize*4 + 123)
+	expectedHashList, expectedSize := serialIntegrityHash(t, content)
+
+	for _, offset := range []int{0, 100, testSegmentSize, testSegmentSize*2 + 500, len(content)} {
+		hasher := NewHasher(testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+		hasher.Init()
+		require.NoError(t, hasher.Append(content[:offset]))
+		state, err := hasher.MarshalBinary()
+		require.NoError(t, err)
+
+		for _, c := range []struct {
+			name     string
+			restored *IntegrityHasher
+		}{
+			{"NewHasher", NewHasher(testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks)},
+			{"empty", &IntegrityHasher{}},
+		} {
+			t.Run(fmt.Sprintf("%s at %d", c.name, offset), func(t *testing.T) {
+				require.NoError(t, c.restored.UnmarshalBinary(state))
+				require.NoError(t, c.restored.Append(content[offset:]))
+				checkHasherResult(t, c.restored, expectedHashList, expectedSize)
+			})
+		}
+	}
+}
+
+func TestIntegrityHasherMarshalError(t *testing.T) {
+	hasher := NewHasher(testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+	hasher.Init()
+	require.NoError(t, hasher.Append(newTestContent(testSegmentSize+1)))
+	state, err := hasher.MarshalBinary()
+	require.NoError(t, err)
+	invalidVersion := append([]byte{}, state...)
+	invalidVersion[len(hasherStateMagic)]++
+
+	for _, c := range []struct {
+		name   string
+		hasher *IntegrityHasher
+		state  []byte
+		err    error
+	}{
+		{"segment size", NewHasher(testSegmentSize*2, redundancy.DataBlocks, redundancy.ParityBlocks), state,
+			ErrHasherStateMismatch},
+		{"data shards", NewHasher(testSegmentSize, redundancy.DataBlocks+1, redundancy.ParityBlocks), state,
+			ErrHasherStateMismatch},
+		{"redundancy type", NewHasherWithRedundancyType(testSegmentSize, redundancy.DataBlocks,
+			redundancy.ParityBlocks, storagetypes.REDUNDANCY_REPLICA_TYPE), state, ErrHasherStateMismatch},
+		{"nil", &IntegrityHasher{}, nil, ErrInvalidHasherState},
+		{"identifier", &IntegrityHasher{}, []byte("invalid state"), ErrInvalidHasherState},
+		{"truncated", &IntegrityHasher{}, state[:len(state)-1], ErrInvalidHasherState},
+		{"trailing data", &IntegrityHasher{}, append(state[:len(state):len(state)], 0), ErrInvalidHasherState},
+		{"version", &IntegrityHasher{}, invalidVersion, ErrInvalidHasherState},
+	} {
+		if err := c.hasher.UnmarshalBinary(c.state); !errors.Is(err, c.err) {
+			t.Errorf("%s: UnmarshalBinary returned %v, expected %v", c.name, err, c.err)
+		}
+	}
+}
+
+// endlessReader generates data endlessly, the onRead callback is called before each read
+type endlessReader struct {
+	onRead func()
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	r.onRead()
+	for i := range p {
+		p[i] = byte(i)
+	}
+	return len(p), nil
+}
+
+// TestComputeIntegrityHashCancel cancel the computing in the middle of reading, the functions should return
+// ctx.Err() and no goroutines should be leaked
+func TestComputeIntegrityHashCancel(t *testing.T) {
+	goroutineNum := runtime.NumGoroutine()
+	filePath := filepath.Join(t.TempDir(), "object")
+	require.NoError(t, os.WriteFile(filePath, make([]byte, testSegmentSize*10), 0o600))
+
+	// cancelAfterReads returns the reader which cancels the ctx at the 200th read
+	cancelAfterReads := func(cancel context.CancelFunc, readTimes *int) io.Reader {
+		return &endlessReader{onRead: func() {
+			*readTimes++
+			if *readTimes == 200 {
+				cancel()
+			}
+		}}
+	}
+	for _, c := range []struct {
+		name    string
+		compute func(ctx context.Context, cancel context.CancelFunc, readTimes *int) error
+		err     error
+	}{
+		{"serial", func(ctx context.Context, cancel context.CancelFunc, readTimes *int) error {
+			_, _, _, err := ComputeIntegrityHashWithContext(ctx, cancelAfterReads(cancel, readTimes), testSegmentSize,
+				redundancy.DataBlocks, redundancy.ParityBlocks, true)
+			return err
+		}, context.Canceled},
+		{"parallel", func(ctx context.Context, cancel context.CancelFunc, readTimes *int) error {
+			_, _, _, err := ComputeIntegrityHashWithContext(ctx, cancelAfterReads(cancel, readTimes), testSegmentSize,
+				redundancy.DataBlocks, redundancy.ParityBlocks, false)
+			return err
+		}, context.Canceled},
+		{"parallel timeout", func(ctx context.Context, _ context.CancelFunc, _ *int) error {
+			ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+			defer cancel()
+			_, _, _, err := ComputeIntegrityHashParallelWithContext(ctx, &endlessReader{onRead: func() {}},
+				testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+			return err
+		}, context.DeadlineExceeded},
+		{"file", func(ctx context.Context, cancel context.CancelFunc, _ *int) error {
+			cancel()
+			_, _, _, err := ComputerHashFromFileWithContext(ctx, filePath, testSegmentSize, redundancy.DataBlocks,
+				redundancy.ParityBlocks)
+			return err
+		}, context.Canceled},
+	} {
+		ctx, cancel := context.WithCancel(context.Background())
+		readTimes := 0
+		if err := c.compute(ctx, cancel, &readTimes); !errors.Is(err, c.err) {
+			t.Errorf("%s: returned %v, expected %v", c.name, err, c.err)
+		}
+		// the reading stops soon after the ctx is canceled
+		if readTimes > 201 {
+			t.Errorf("%s: read %d times after canceled", c.name, readTimes)
+		}
+		cancel()
+	}
+
+	// the hash workers should have exited
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > goroutineNum && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if num := runtime.NumGoroutine(); num > goroutineNum {
+		t.Errorf("%d goroutines leaked", num-goroutineNum)
+	}
+}
+
+// TestComputeIntegrityHashWithOptions compare the results computed with different options
+func TestComputeIntegrityHashWithOptions(t *testing.T) {
+	content := newTestContent(testSegmentSize*20 + 33)
+	expectedHashList, expectedSize := serialIntegrityHash(t, content)
+
+	for _, c := range []struct {
+		name string
+		opts []Option
+	}{
+		{"default", nil},
+		{"serial", []Option{WithSerial(true)}},
+		{"one worker", []Option{WithWorkerNum(1), WithMaxInflightSegments(1)}},
+		{"more workers than inflight", []Option{WithWorkerNum(8), WithMaxInflightSegments(3)}},
+		{"budget less than segment", []Option{WithWorkerNum(4), WithMemoryBudget(testSegmentSize / 2)}},
+		{"budget of segments", []Option{WithMemoryBudget(testSegmentSize * 5)}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			hashList, size, redundancyType, err := ComputeIntegrityHashWithOptions(context.Background(),
+				bytes.NewReader(content), testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks, c.opts...)
+			require.NoError(t, err)
+			if size != expectedSize {
+				t.Errorf("content length %d, expected %d", size, expectedSize)
+			}
+			if redundancyType != storagetypes.REDUNDANCY_EC_TYPE {
+				t.Errorf("redundancy type %s, expected %s", redundancyType, storagetypes.REDUNDANCY_EC_TYPE)
+			}
+			checkHashList(t, expectedHashList, hashList)
+		})
+	}
+}
+
+func TestHashOptionsInflightSegments(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		opts     []Option
+		expected int
+	}{
+		{"budget less than segment", []Option{WithMemoryBudget(testSegmentSize - 1)}, 1},
+		{"budget of segments", []Option{WithMemoryBudget(testSegmentSize * 4)}, 4},
+		{"max inflight", []Option{WithMemoryBudget(testSegmentSize * 4), WithMaxInflightSegments(2)}, 2},
+		{"default", nil, jobChannelSize},
+	} {
+		if num := newHashOptions(c.opts...).inflightSegments(testSegmentSize); num != c.expected {
+			t.Errorf("%s: %d inflight segments, expected %d", c.name, num, c.expected)
+		}
+	}
+	if num := newHashOptions(WithWorkerNum(0)).workerNum; num < 1 {
+		t.Errorf("%d workers, expected at least 1", num)
+	}
+}
+
+// TestComputeIntegrityHashLogger checks the error is logged by the logger of options
+func TestComputeIntegrityHashLogger(t *testing.T) {
+	readErr := errors.New("mock read error")
+	for _, isSerial := range []bool{true, false} {
+		var logBuffer bytes.Buffer
+		_, _, _, err := ComputeIntegrityHashWithOptions(context.Background(), iotest.ErrReader(readErr),
+			testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks, WithSerial(isSerial),
+			WithLogger(zerolog.New(&logBuffer)))
+		if !errors.Is(err, readErr) {
+			t.Errorf("serial %t: returned %v, expected %v", isSerial, err, readErr)
+		}
+		if !strings.Contains(logBuffer.String(), readErr.Error()) {
+			t.Errorf("serial %t: the error is not logged: %q", isSerial, logBuffer.String())
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package hash
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+const (
+	hasherStateMagic   = "gnfdih"
+	hasherStateVersion = byte(1)
+	// maxShardsNum is the max shards number supported by the erasure encoder
+	maxShardsNum = 256
+)
+
+var (
+	// ErrInvalidHasherState indicates the marshaled state of IntegrityHasher is malformed
+	ErrInvalidHasherState = errors.New("invalid integrity hasher state")
+	// ErrHasherStateMismatch indicates the marshaled state was produced with another segment size, EC geometry or
+	// redundancy type
+	ErrHasherStateMismatch = errors.New("integrity hasher state mismatch")
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler, the state contains the segment size, the EC geometry,
+// the redundancy type, the checksums computed so far and the buffered data which has not been hashed
+func (i *IntegrityHasher) MarshalBinary() ([]byte, error) {
+	size := len(hasherStateMagic) + 1 + 8 + 4 + 4 + 4 + 8 + 4 + len(i.buffer)
+	for _, checksum := range i.segHashes {
+		size += 4 + len(checksum)
+	}
+	for _, pieceHashes := range i.ecDataHashes {
+		size += 4
+		for _, checksum := range pieceHashes {
+			size += 4 + len(checksum)
+		}
+	}
+
+	b := make([]byte, 0, size)
+	b = append(b, hasherStateMagic...)
+	b = append(b, hasherStateVersion)
+	b = binary.BigEndian.AppendUint64(b, uint64(i.segmentSize))
+	b = binary.BigEndian.AppendUint32(b, uint32(i.dataShards))
+	b = binary.BigEndian.AppendUint32(b, uint32(i.parityShards))
+	b = binary.BigEndian.AppendUint32(b, uint32(i.redundancyType))
+	b = binary.BigEndian.AppendUint64(b, uint64(i.contentLen))
+	b = appendChecksumList(b, i.segHashes)
+	for index := 0; index < i.dataShards+i.parityShards; index++ {
+		var pieceHashes [][]byte
+		if index < len(i.ecDataHashes) {
+			pieceHashes = i.ecDataHashes[index]
+		}
+		b = appendChecksumList(b, pieceHashes)
+	}
+	b = appendBytes(b, i.buffer)
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, it restores the state produced by MarshalBinary.
+// If the hasher has been created by NewHasher, the segment size, the EC geometry and the redundancy type of the state
+// should be the same.
+func (i *IntegrityHasher) UnmarshalBinary(data []byte) error {
+	if len(data) < len(hasherStateMagic)+1 || string(data[:len(hasherStateMagic)]) != hasherStateMagic {
+		return fmt.Errorf("%w: unknown identifier", ErrInvalidHasherState)
+	}
+	version := data[len(hasherStateMagic)]
+	if version != hasherStateVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidHasherState, version)
+	}
+
+	d := &stateDecoder{data: data[len(hasherStateMagic)+1:]}
+	segmentSize := int64(d.uint64())
+	dataShards := int(d.uint32())
+	parityShards := int(d.uint32())
+	redundancyType := storagetypes.RedundancyType(d.uint32())
+	contentLen := int64(d.uint64())
+	if d.err != nil {
+		return d.err
+	}
+	if segmentSize <= 0 || dataShards <= 0 || parityShards < 0 || dataShards+parityShards > maxShardsNum || contentLen < 0 {
+		return fmt.Errorf("%w: invalid segment size or EC geometry", ErrInvalidHasherState)
+	}
+	if err := checkRedundancyType(redundancyType); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidHasherState, err.Error())
+	}
+	if i.segmentSize != 0 && (i.segmentSize != segmentSize || i.dataShards != dataShards ||
+		i.parityShards != parityShards || i.redundancyType != redundancyType) {
+		return fmt.Errorf("%w: segment size %d, data shards %d, parity shards %d, redundancy type %s",
+			ErrHasherStateMismatch, segmentSize, dataShards, parityShards, redundancyType)
+	}
+
+	segHashes := d.checksumList()
+	ecDataHashes := make([][][]byte, dataShards+parityShards)
+	for index := range ecDataHashes {
+		ecDataHashes[index] = d.checksumList()
+		if d.err == nil && len(ecDataHashes[index]) != len(segHashes) {
+			return fmt.Errorf("%w: piece checksums number mismatch", ErrInvalidHasherState)
+		}
+	}
+	buffer := d.bytes()
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.data) != 0 {
+		return fmt.Errorf("%w: unexpected trailing data", ErrInvalidHasherState)
+	}
+	if int64(len(buffer)) >= segmentSize || contentLen != int64(len(segHashes))*segmentSize {
+		return fmt.Errorf("%w: content length mismatch", ErrInvalidHasherState)
+	}
+
+	i.segmentSize = segmentSize
+	i.dataShards = dataShards
+	i.parityShards = parityShards
+	i.redundancyType = redundancyType
+	i.contentLen = contentLen
+	i.segHashes = segHashes
+	i.ecDataHashes = ecDataHashes
+	i.buffer = buffer
+	return nil
+}
+
+func appendBytes(b []byte, data []byte) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(data)))
+	return append(b, data...)
+}
+
+func appendChecksumList(b []byte, checksumList [][]byte) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(checksumList)))
+	for _, checksum := range checksumList {
+		b = appendBytes(b, checksum)
+	}
+	return b
+}
+
+// stateDecoder reads the fields of marshaled state in order, the first error is kept and stops the decoding
+type stateDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *stateDecoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.data) < n {
+		d.err = fmt.Errorf("%w: unexpected end of data", ErrInvalidHasherState)
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *stateDecoder) uint32() uint32 {
+	b := d.next(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+func (d *stateDecoder) uint64() uint64 {
+	b := d.next(8)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+func (d *stateDecoder) bytes() []byte {
+	n := d.uint32()
+	b := d.next(int(n))
+	if b == nil {
+		return nil
+	}
+	return append(make([]byte, 0, len(b)), b...)
+}
+
+func (d *stateDecoder) checksumList() [][]byte {
+	n := d.uint32()
+	// every checksum takes at least 4 bytes of length prefix
+	if d.err == nil && uint64(n)*4 > uint64(len(d.data)) {
+		d.err = fmt.Errorf("%w: unexpected end of data", ErrInvalidHasherState)
+	}
+	if d.err != nil {
+		return nil
+	}
+	checksumList := make([][]byte, 0, n)
+	for index := uint32(0); index < n && d.err == nil; index++ {
+		checksumList = append(checksumList, d.bytes())
+	}
+	return checksumList
+}
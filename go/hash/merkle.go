@@ -0,0 +1,205 @@
+package hash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// merkleProofVersion is the version of the serialized MerkleProof
+	merkleProofVersion = byte(1)
+	// the domain separation prefixes of the leaf and internal node hashes
+	merkleLeafPrefix = byte(0)
+	merkleNodePrefix = byte(1)
+)
+
+var (
+	// ErrEmptyChecksumList indicates a merkle tree is built from an empty checksum list
+	ErrEmptyChecksumList = errors.New("empty checksum list")
+	// ErrLeafIndexOutOfRange indicates the leaf index is not in the merkle tree
+	ErrLeafIndexOutOfRange = errors.New("leaf index out of range")
+	// ErrInvalidMerkleProof indicates the merkle proof is malformed or does not match the root
+	ErrInvalidMerkleProof = errors.New("invalid merkle proof")
+)
+
+// MerkleTree is the merkle tree built from a checksum list, it is an opt-in commitment of the checksum list
+// which allows to verify one checksum with a proof of log size instead of the whole list.
+// The leaves are sha256(0x00 || checksum) and the internal nodes are sha256(0x01 || left || right),
+// the last node of a level without sibling is promoted to the upper level.
+type MerkleTree struct {
+	// levels[0] is the leaves and the last level only contains the root
+	levels [][][]byte
+}
+
+// MerkleProof is the sibling hashes from the leaf to the root
+type MerkleProof struct {
+	LeafCount int
+	Index     int
+	Siblings  [][]byte
+}
+
+// BuildMerkleTree builds the merkle tree of the checksum list
+func BuildMerkleTree(checksums [][]byte) (*MerkleTree, error) {
+	if len(checksums) == 0 {
+		return nil, ErrEmptyChecksumList
+	}
+
+	level := make([][]byte, len(checksums))
+	for index, checksum := range checksums {
+		level[index] = merkleLeafHash(checksum)
+	}
+	levels := [][][]byte{level}
+	for len(level) > 1 {
+		upper := make([][]byte, 0, (len(level)+1)/2)
+		for index := 0; index < len(level); index += 2 {
+			if index+1 == len(level) {
+				upper = append(upper, level[index])
+				continue
+			}
+			upper = append(upper, merkleNodeHash(level[index], level[index+1]))
+		}
+		levels = append(levels, upper)
+		level = upper
+	}
+	return &MerkleTree{levels: levels}, nil
+}
+
+// GenerateMerkleIntegrityHash generates the merkle root of the checksum list, it is the merkle commitment
+// counterpart of GenerateIntegrityHash
+func GenerateMerkleIntegrityHash(checksumList [][]byte) ([]byte, error) {
+	tree, err := BuildMerkleTree(checksumList)
+	if err != nil {
+		return nil, err
+	}
+	return tree.Root(), nil
+}
+
+// Root returns the merkle root
+func (t *MerkleTree) Root() []byte {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// LeafCount returns the number of checksums in the tree
+func (t *MerkleTree) LeafCount() int {
+	return len(t.levels[0])
+}
+
+// Proof returns the inclusion proof of the checksum at index
+func (t *MerkleTree) Proof(index int) (*MerkleProof, error) {
+	if index < 0 || index >= t.LeafCount() {
+		return nil, fmt.Errorf("%w: %d", ErrLeafIndexOutOfRange, index)
+	}
+
+	proof := &MerkleProof{LeafCount: t.LeafCount(), Index: index}
+	pos := index
+	for _, level := range t.levels[:len(t.levels)-1] {
+		if pos%2 == 1 {
+			proof.Siblings = append(proof.Siblings, level[pos-1])
+		} else if pos+1 < len(level) {
+			proof.Siblings = append(proof.Siblings, level[pos+1])
+		}
+		pos /= 2
+	}
+	return proof, nil
+}
+
+// VerifyProof verifies the checksum leaf is at index of the merkle tree of root with leafCount leaves, the leafCount
+// must come from the trusted state such as the length of the checksum list, the proof of another leaf count is rejected
+func VerifyProof(root []byte, leafCount, index int, leaf []byte, proof *MerkleProof) error {
+	if leafCount <= 0 {
+		return fmt.Errorf("%w: invalid leaf count %d", ErrInvalidMerkleProof, leafCount)
+	}
+	if index < 0 || index >= leafCount {
+		return fmt.Errorf("%w: %d", ErrLeafIndexOutOfRange, index)
+	}
+	if proof == nil || proof.Index != index || proof.LeafCount != leafCount {
+		return fmt.Errorf("%w: proof mismatch with index %d of %d leaves", ErrInvalidMerkleProof, index, leafCount)
+	}
+
+	hash := merkleLeafHash(leaf)
+	siblings := proof.Siblings
+	for pos, levelSize := index, leafCount; levelSize > 1; pos, levelSize = pos/2, (levelSize+1)/2 {
+		if pos%2 == 0 && pos+1 == levelSize {
+			// the node is promoted without sibling
+			continue
+		}
+		if len(siblings) == 0 {
+			return fmt.Errorf("%w: too few siblings", ErrInvalidMerkleProof)
+		}
+		if pos%2 == 1 {
+			hash = merkleNodeHash(siblings[0], hash)
+		} else {
+			hash = merkleNodeHash(hash, siblings[0])
+		}
+		siblings = siblings[1:]
+	}
+	if len(siblings) != 0 {
+		return fmt.Errorf("%w: too many siblings", ErrInvalidMerkleProof)
+	}
+	if !bytes.Equal(hash, root) {
+		return fmt.Errorf("%w: root mismatch", ErrInvalidMerkleProof)
+	}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, the format is
+// version(1 byte) | leaf count(4 bytes) | index(4 bytes) | sibling count(1 byte) | siblings(32 bytes each)
+func (p *MerkleProof) MarshalBinary() ([]byte, error) {
+	if len(p.Siblings) > 255 {
+		return nil, fmt.Errorf("%w: too many siblings", ErrInvalidMerkleProof)
+	}
+	b := make([]byte, 0, 10+len(p.Siblings)*sha256.Size)
+	b = append(b, merkleProofVersion)
+	b = binary.BigEndian.AppendUint32(b, uint32(p.LeafCount))
+	b = binary.BigEndian.AppendUint32(b, uint32(p.Index))
+	b = append(b, byte(len(p.Siblings)))
+	for _, sibling := range p.Siblings {
+		if len(sibling) != sha256.Size {
+			return nil, fmt.Errorf("%w: invalid sibling length %d", ErrInvalidMerkleProof, len(sibling))
+		}
+		b = append(b, sibling...)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (p *MerkleProof) UnmarshalBinary(data []byte) error {
+	if len(data) < 10 {
+		return fmt.Errorf("%w: too short", ErrInvalidMerkleProof)
+	}
+	if data[0] != merkleProofVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidMerkleProof, data[0])
+	}
+	siblingNum := int(data[9])
+	if len(data) != 10+siblingNum*sha256.Size {
+		return fmt.Errorf("%w: length mismatch", ErrInvalidMerkleProof)
+	}
+
+	siblings := make([][]byte, siblingNum)
+	for index := range siblings {
+		offset := 10 + index*sha256.Size
+		siblings[index] = append([]byte{}, data[offset:offset+sha256.Size]...)
+	}
+	p.LeafCount = int(binary.BigEndian.Uint32(data[1:5]))
+	p.Index = int(binary.BigEndian.Uint32(data[5:9]))
+	p.Siblings = siblings
+	return nil
+}
+
+func merkleLeafHash(checksum []byte) []byte {
+	hash := sha256.New()
+	hash.Write([]byte{merkleLeafPrefix})
+	hash.Write(checksum)
+	return hash.Sum(nil)
+}
+
+func merkleNodeHash(left, right []byte) []byte {
+	hash := sha256.New()
+	hash.Write([]byte{merkleNodePrefix})
+	hash.Write(left)
+	hash.Write(right)
+	return hash.Sum(nil)
+}
@@ -0,0 +1,164 @@
+package hash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestChecksums returns the checksums of count random contents
+func newTestChecksums(count int) [][]byte {
+	checksums := make([][]byte, count)
+	for i := range checksums {
+		checksums[i] = GenerateChecksum(newTestContent(64))
+	}
+	return checksums
+}
+
+func TestMerkleTree(t *testing.T) {
+	checksums := newTestChecksums(3)
+
+	// root = H(1 || H(1 || H(0 || c0) || H(0 || c1)) || H(0 || c2))
+	leaves := make([][]byte, 3)
+	for i, checksum := range checksums {
+		leaf := sha256.Sum256(append([]byte{0}, checksum...))
+		leaves[i] = leaf[:]
+	}
+	node := sha256.Sum256(append(append([]byte{1}, leaves[0]...), leaves[1]...))
+	root := sha256.Sum256(append(append([]byte{1}, node[:]...), leaves[2]...))
+
+	tree, err := BuildMerkleTree(checksums)
+	require.NoError(t, err)
+	if tree.LeafCount() != 3 {
+		t.Errorf("leaf count %d, expected 3", tree.LeafCount())
+	}
+	if !bytes.Equal(root[:], tree.Root()) {
+		t.Errorf("root %x, expected %x", tree.Root(), root)
+	}
+	merkleRoot, err := GenerateMerkleIntegrityHash(checksums)
+	require.NoError(t, err)
+	if !bytes.Equal(root[:], merkleRoot) {
+		t.Errorf("merkle integrity hash %x, expected %x", merkleRoot, root)
+	}
+
+	if _, err := BuildMerkleTree(nil); !errors.Is(err, ErrEmptyChecksumList) {
+		t.Errorf("returned %v of the empty list, expected %v", err, ErrEmptyChecksumList)
+	}
+	if _, err := tree.Proof(3); !errors.Is(err, ErrLeafIndexOutOfRange) {
+		t.Errorf("returned %v of index 3, expected %v", err, ErrLeafIndexOutOfRange)
+	}
+}
+
+func TestMerkleProof(t *testing.T) {
+	for leafCount := 1; leafCount <= 33; leafCount++ {
+		checksums := newTestChecksums(leafCount)
+		tree, err := BuildMerkleTree(checksums)
+		require.NoError(t, err)
+
+		for index, checksum := range checksums {
+			proof, err := tree.Proof(index)
+			require.NoError(t, err)
+			// the proof size is log of the leaf count
+			if 1<<len(proof.Siblings) > 2*leafCount {
+				t.Errorf("%d siblings in the proof of %d leaves", len(proof.Siblings), leafCount)
+			}
+			b, err := proof.MarshalBinary()
+			require.NoError(t, err)
+			decoded := &MerkleProof{}
+			require.NoError(t, decoded.UnmarshalBinary(b))
+			if decoded.LeafCount != proof.LeafCount {
+				t.Errorf("decoded leaf count %d, expected %d", decoded.LeafCount, proof.LeafCount)
+			}
+
+			// the proof of another leaf or another index should fail, the other leaf of the single leaf tree is
+			// the leaf itself
+			nextIndexErr, wrongChecksumErr := ErrInvalidMerkleProof, ErrInvalidMerkleProof
+			if index+1 == leafCount {
+				nextIndexErr = ErrLeafIndexOutOfRange
+			}
+			if leafCount == 1 {
+				wrongChecksumErr = nil
+			}
+			for _, c := range []struct {
+				name     string
+				index    int
+				checksum []byte
+				proof    *MerkleProof
+				err      error
+			}{
+				{"proof", index, checksum, proof, nil},
+				{"decoded proof", index, checksum, decoded, nil},
+				{"next index", index + 1, checksum, proof, nextIndexErr},
+				{"wrong checksum", index, checksums[(index+1)%leafCount], proof, wrongChecksumErr},
+			} {
+				err := VerifyProof(tree.Root(), leafCount, c.index, c.checksum, c.proof)
+				if !errors.Is(err, c.err) {
+					t.Errorf("%s of leaf %d/%d: returned %v, expected %v", c.name, index, leafCount, err, c.err)
+				}
+			}
+		}
+	}
+}
+
+func TestMerkleProofMalformed(t *testing.T) {
+	checksums := newTestChecksums(5)
+	tree, err := BuildMerkleTree(checksums)
+	require.NoError(t, err)
+	proof, err := tree.Proof(2)
+	require.NoError(t, err)
+	b, err := proof.MarshalBinary()
+	require.NoError(t, err)
+
+	for _, c := range []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"unknown version", append([]byte{merkleProofVersion + 1}, b[1:]...)},
+		{"truncated", b[:len(b)-1]},
+		{"trailing data", append(append([]byte{}, b...), 0)},
+	} {
+		if err := (&MerkleProof{}).UnmarshalBinary(c.data); !errors.Is(err, ErrInvalidMerkleProof) {
+			t.Errorf("%s: returned %v, expected %v", c.name, err, ErrInvalidMerkleProof)
+		}
+	}
+
+	// the proof of leaf 2 of a 3 leaves tree with the forged leaf count 2 is a valid path of index 1, it is
+	// rejected since the leaf count differs from the trusted one
+	smallTree, err := BuildMerkleTree(checksums[:3])
+	require.NoError(t, err)
+	smallProof, err := smallTree.Proof(2)
+	require.NoError(t, err)
+	if err := VerifyProof(smallTree.Root(), 3, 2, checksums[2], smallProof); err != nil {
+		t.Errorf("returned %v of the proof of leaf 2 of 3", err)
+	}
+
+	for _, c := range []struct {
+		name      string
+		root      []byte
+		leafCount int
+		index     int
+		proof     *MerkleProof
+	}{
+		{"dropped sibling", tree.Root(), len(checksums), 2,
+			&MerkleProof{LeafCount: proof.LeafCount, Index: proof.Index, Siblings: proof.Siblings[1:]}},
+		{"added sibling", tree.Root(), len(checksums), 2, &MerkleProof{LeafCount: proof.LeafCount, Index: proof.Index,
+			Siblings: append(append([][]byte{}, proof.Siblings...), tree.Root())}},
+		{"nil proof", tree.Root(), len(checksums), 2, nil},
+		// the leaf count comes from the trusted state instead of the proof
+		{"larger leaf count", tree.Root(), len(checksums) + 1, 2, proof},
+		{"zero leaf count", tree.Root(), 0, 2, proof},
+		{"forged leaf count", smallTree.Root(), 3, 1,
+			&MerkleProof{LeafCount: 2, Index: 1, Siblings: smallProof.Siblings}},
+		{"forged index", smallTree.Root(), 3, 1,
+			&MerkleProof{LeafCount: 3, Index: 1, Siblings: smallProof.Siblings}},
+	} {
+		err := VerifyProof(c.root, c.leafCount, c.index, checksums[2], c.proof)
+		if !errors.Is(err, ErrInvalidMerkleProof) {
+			t.Errorf("%s: returned %v, expected %v", c.name, err, ErrInvalidMerkleProof)
+		}
+	}
+}
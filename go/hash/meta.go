@@ -0,0 +1,94 @@
+package hash
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+// IntegrityMeta describes the integrity hashes of an object and the checksum lists to compute them,
+// the checksum lists are needed by the PrimarySP and SecondarySPs to answer the challenges
+type IntegrityMeta struct {
+	// IntegrityHashes contains the integrity hash of segments, followed by the integrity hashes of the pieces
+	// of each redundancy index
+	IntegrityHashes [][]byte
+	// SegmentChecksums is the checksum list of all the segments, stored by the PrimarySP
+	SegmentChecksums [][]byte
+	// PieceChecksums is the checksum lists of pieces indexed by the redundancy index, stored by the SecondarySPs.
+	// In the replica type, each list is the same as SegmentChecksums.
+	PieceChecksums [][][]byte
+	ContentLength  int64
+	RedundancyType storagetypes.RedundancyType
+}
+
+func newIntegrityMeta(segChecksumList [][]byte, encodeDataHash [][][]byte, contentLen int64,
+	redundancyType storagetypes.RedundancyType,
+) *IntegrityMeta {
+	return &IntegrityMeta{
+		IntegrityHashes:  generateIntegrityHashList(segChecksumList, encodeDataHash),
+		SegmentChecksums: segChecksumList,
+		PieceChecksums:   encodeDataHash,
+		ContentLength:    contentLen,
+		RedundancyType:   redundancyType,
+	}
+}
+
+// integrityHashResult converts the meta to the results of the integrity hash functions
+func integrityHashResult(meta *IntegrityMeta, err error) ([][]byte, int64, storagetypes.RedundancyType, error) {
+	if err != nil {
+		return nil, 0, storagetypes.REDUNDANCY_EC_TYPE, err
+	}
+	return meta.IntegrityHashes, meta.ContentLength, meta.RedundancyType, nil
+}
+
+// ComputeIntegrityMeta is the same as ComputeIntegrityHashWithOptions, and returns the checksum lists of segments
+// and pieces as well
+func ComputeIntegrityMeta(ctx context.Context, reader io.Reader, segmentSize int64, dataShards, parityShards int,
+	opts ...Option,
+) (*IntegrityMeta, error) {
+	o := newHashOptions(opts...)
+	if err := checkRedundancyType(o.redundancyType); err != nil {
+		return nil, err
+	}
+	if o.isSerial {
+		return computeIntegrityHashSerial(ctx, reader, segmentSize, dataShards, parityShards, o)
+	}
+	return computeIntegrityHashParallel(ctx, reader, segmentSize, dataShards, parityShards, o)
+}
+
+// ComputeIntegrityMetaFromFile is the same as ComputerHashFromFileWithContext, and returns the checksum lists of
+// segments and pieces as well
+func ComputeIntegrityMetaFromFile(ctx context.Context, filePath string, segmentSize int64, dataShards,
+	parityShards int, opts ...Option,
+) (*IntegrityMeta, error) {
+	o := newHashOptions(opts...)
+	f, err := os.Open(filePath)
+	if err != nil {
+		o.logger.Error().Msg("failed to open file:" + err.Error())
+		return nil, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		o.logger.Error().Msg("failed to stat file:" + err.Error())
+		return nil, err
+	}
+	// the size of special files such as pipes is unknown, read them as a stream
+	if !stat.Mode().IsRegular() {
+		return ComputeIntegrityMeta(ctx, f, segmentSize, dataShards, parityShards, opts...)
+	}
+	return ComputeIntegrityMetaFromReaderAt(ctx, f, stat.Size(), segmentSize, dataShards, parityShards, opts...)
+}
+
+// ComputeIntegrityMetaFromBuffer is the same as ComputerHashFromBuffer, and returns the checksum lists of segments
+// and pieces as well
+func ComputeIntegrityMetaFromBuffer(content []byte, segmentSize int64, dataShards, parityShards int,
+	opts ...Option,
+) (*IntegrityMeta, error) {
+	return ComputeIntegrityMeta(context.Background(), bytes.NewReader(content), segmentSize, dataShards, parityShards,
+		opts...)
+}
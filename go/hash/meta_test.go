@@ -0,0 +1,218 @@
+package hash
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-common/go/redundancy"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+// TestIntegrityMeta compare the meta from all the hashing entry points, and challenge the segments and pieces with
+// the checksum lists of meta
+func TestIntegrityMeta(t *testing.T) {
+	content := newTestContent(testSegmentSize*3 + 777)
+	ctx := context.Background()
+	expectedHashList, _ := serialIntegrityHash(t, content)
+
+	meta, err := ComputeIntegrityMeta(ctx, bytes.NewReader(content), testSegmentSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks, WithSerial(true))
+	require.NoError(t, err)
+	if meta.ContentLength != int64(len(content)) {
+		t.Errorf("content length %d, expected %d", meta.ContentLength, len(content))
+	}
+	if meta.RedundancyType != storagetypes.REDUNDANCY_EC_TYPE {
+		t.Errorf("redundancy type %s, expected %s", meta.RedundancyType, storagetypes.REDUNDANCY_EC_TYPE)
+	}
+	require.Len(t, meta.SegmentChecksums, 4)
+	require.Len(t, meta.PieceChecksums, redundancy.DataBlocks+redundancy.ParityBlocks)
+	checkHashList(t, expectedHashList, meta.IntegrityHashes)
+
+	filePath := filepath.Join(t.TempDir(), "object")
+	require.NoError(t, os.WriteFile(filePath, content, 0o600))
+	for _, c := range []struct {
+		name    string
+		compute func() (*IntegrityMeta, error)
+	}{
+		{"parallel", func() (*IntegrityMeta, error) {
+			return ComputeIntegrityMeta(ctx, bytes.NewReader(content), testSegmentSize, redundancy.DataBlocks,
+				redundancy.ParityBlocks)
+		}},
+		{"ReaderAt", func() (*IntegrityMeta, error) {
+			return ComputeIntegrityMetaFromReaderAt(ctx, bytes.NewReader(content), int64(len(content)),
+				testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+		}},
+		{"buffer", func() (*IntegrityMeta, error) {
+			return ComputeIntegrityMetaFromBuffer(content, testSegmentSize, redundancy.DataBlocks,
+				redundancy.ParityBlocks)
+		}},
+		{"file", func() (*IntegrityMeta, error) {
+			return ComputeIntegrityMetaFromFile(ctx, filePath, testSegmentSize, redundancy.DataBlocks,
+				redundancy.ParityBlocks)
+		}},
+		{"IntegrityHasher", func() (*IntegrityMeta, error) {
+			hasher := NewHasher(testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+			hasher.Init()
+			if err := hasher.Append(content); err != nil {
+				return nil, err
+			}
+			return hasher.FinishMeta()
+		}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := c.compute()
+			require.NoError(t, err)
+			if !reflect.DeepEqual(meta, actual) {
+				t.Errorf("meta mismatch, expected %+v, got %+v", meta, actual)
+			}
+		})
+	}
+
+	for segIndex := 0; segIndex*testSegmentSize < len(content); segIndex++ {
+		end := (segIndex + 1) * testSegmentSize
+		if end > len(content) {
+			end = len(content)
+		}
+		// limit the capacity to avoid the encoder writing the parity data into the content
+		segment := content[segIndex*testSegmentSize : end : end]
+		if err := ChallengePieceHash(meta.IntegrityHashes[0], meta.SegmentChecksums, segIndex, segment); err != nil {
+			t.Errorf("segment %d: %v", segIndex, err)
+		}
+
+		pieces, err := redundancy.EncodeRawSegment(segment, redundancy.DataBlocks, redundancy.ParityBlocks)
+		require.NoError(t, err)
+		for redundancyIndex, piece := range pieces {
+			err := ChallengePieceHash(meta.IntegrityHashes[redundancyIndex+1], meta.PieceChecksums[redundancyIndex],
+				segIndex, piece)
+			if err != nil {
+				t.Errorf("piece %d of segment %d: %v", redundancyIndex, segIndex, err)
+			}
+		}
+	}
+}
+
+// TestReplicaRedundancyType compute the hashes in the replica type, the checksum list of every replica should be the
+// same as the segment checksum list
+func TestReplicaRedundancyType(t *testing.T) {
+	content := newTestContent(testSegmentSize*5 + 1)
+	ctx := context.Background()
+	replicaNum := redundancy.DataBlocks + redundancy.ParityBlocks
+	replicaOpt := WithRedundancyType(storagetypes.REDUNDANCY_REPLICA_TYPE)
+	ecHashList, _ := serialIntegrityHash(t, content)
+
+	meta, err := ComputeIntegrityMeta(ctx, bytes.NewReader(content), testSegmentSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks, WithSerial(true), replicaOpt)
+	require.NoError(t, err)
+	if meta.RedundancyType != storagetypes.REDUNDANCY_REPLICA_TYPE {
+		t.Errorf("redundancy type %s, expected %s", meta.RedundancyType, storagetypes.REDUNDANCY_REPLICA_TYPE)
+	}
+	require.Len(t, meta.IntegrityHashes, replicaNum+1)
+	require.Len(t, meta.PieceChecksums, replicaNum)
+	if !bytes.Equal(ecHashList[0], meta.IntegrityHashes[0]) {
+		t.Errorf("primary integrity hash differs from the EC type")
+	}
+	for index := 0; index < replicaNum; index++ {
+		if !equalChecksums(meta.SegmentChecksums, meta.PieceChecksums[index]) {
+			t.Errorf("checksum list of replica %d differs from the segment checksum list", index)
+		}
+		if !bytes.Equal(meta.IntegrityHashes[0], meta.IntegrityHashes[index+1]) {
+			t.Errorf("integrity hash of replica %d differs from the primary integrity hash", index)
+		}
+	}
+
+	t.Run("options", func(t *testing.T) {
+		hashList, size, redundancyType, err := ComputeIntegrityHashWithOptions(ctx, bytes.NewReader(content),
+			testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks, replicaOpt)
+		require.NoError(t, err)
+		checkHashList(t, meta.IntegrityHashes, hashList)
+		if size != int64(len(content)) {
+			t.Errorf("content length %d, expected %d", size, len(content))
+		}
+		if redundancyType != storagetypes.REDUNDANCY_REPLICA_TYPE {
+			t.Errorf("redundancy type %s, expected %s", redundancyType, storagetypes.REDUNDANCY_REPLICA_TYPE)
+		}
+	})
+
+	for _, c := range []struct {
+		name    string
+		compute func() (*IntegrityMeta, error)
+	}{
+		{"ReaderAt", func() (*IntegrityMeta, error) {
+			return ComputeIntegrityMetaFromReaderAt(ctx, bytes.NewReader(content), int64(len(content)),
+				testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks, replicaOpt)
+		}},
+		{"restored IntegrityHasher", func() (*IntegrityMeta, error) {
+			hasher := NewHasherWithRedundancyType(testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks,
+				storagetypes.REDUNDANCY_REPLICA_TYPE)
+			hasher.Init()
+			if err := hasher.Append(content[:testSegmentSize+10]); err != nil {
+				return nil, err
+			}
+			state, err := hasher.MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			restored := &IntegrityHasher{}
+			if err := restored.UnmarshalBinary(state); err != nil {
+				return nil, err
+			}
+			if err := restored.Append(content[testSegmentSize+10:]); err != nil {
+				return nil, err
+			}
+			return restored.FinishMeta()
+		}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := c.compute()
+			require.NoError(t, err)
+			if !reflect.DeepEqual(meta, actual) {
+				t.Errorf("meta mismatch, expected %+v, got %+v", meta, actual)
+			}
+		})
+	}
+}
+
+func TestReplicaRedundancyTypeError(t *testing.T) {
+	content := newTestContent(testSegmentSize + 10)
+	ctx := context.Background()
+	unknownOpt := WithRedundancyType(storagetypes.RedundancyType(100))
+
+	hasher := NewHasherWithRedundancyType(testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks,
+		storagetypes.REDUNDANCY_REPLICA_TYPE)
+	hasher.Init()
+	require.NoError(t, hasher.Append(content))
+	state, err := hasher.MarshalBinary()
+	require.NoError(t, err)
+
+	for _, c := range []struct {
+		name string
+		run  func() error
+		err  error
+	}{
+		// the state of the replica type should not be restored by an EC hasher
+		{"restore by EC hasher", func() error {
+			return NewHasher(testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks).UnmarshalBinary(state)
+		}, ErrHasherStateMismatch},
+		{"unknown type", func() error {
+			_, err := ComputeIntegrityMeta(ctx, bytes.NewReader(content), testSegmentSize, redundancy.DataBlocks,
+				redundancy.ParityBlocks, unknownOpt)
+			return err
+		}, ErrUnsupportedRedundancyType},
+		{"unknown type ReaderAt", func() error {
+			_, err := ComputeIntegrityMetaFromReaderAt(ctx, bytes.NewReader(content), int64(len(content)),
+				testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks, unknownOpt)
+			return err
+		}, ErrUnsupportedRedundancyType},
+	} {
+		if err := c.run(); !errors.Is(err, c.err) {
+			t.Errorf("%s: returned %v, expected %v", c.name, err, c.err)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package hash
+
+import (
+	"time"
+
+	"github.com/bnb-chain/greenfield-common/go/redundancy"
+)
+
+// Observer receives the progress and the timing of the hashing, such as rendering a progress bar or exporting the
+// metrics. The callbacks may be called concurrently by the workers, they should return quickly and should not
+// change the results.
+type Observer interface {
+	// OnSegmentRead is called after the segment of segmentID with size bytes is read
+	OnSegmentRead(segmentID int, size int)
+	// OnSegmentHashed is called after the checksums of the segment and its pieces are computed, the duration
+	// includes the erasure encoding
+	OnSegmentHashed(segmentID int, duration time.Duration)
+	// OnSegmentEncoded is called after the segment is erasure encoded, it is not called in the replica type
+	redundancy.EncodeObserver
+	// OnFinish is called after all the segments are hashed successfully with the total size of the content
+	OnFinish(totalSize int64)
+}
+
+// NopObserver is the Observer doing nothing, it is used by default
+type NopObserver struct{}
+
+func (NopObserver) OnSegmentRead(int, int) {}
+
+func (NopObserver) OnSegmentHashed(int, time.Duration) {}
+
+func (NopObserver) OnSegmentEncoded(int, time.Duration) {}
+
+func (NopObserver) OnFinish(int64) {}
@@ -0,0 +1,139 @@
+package hash
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-common/go/redundancy"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+type recordObserver struct {
+	mtx       sync.Mutex
+	readBytes int
+	read      map[int]bool
+	hashed    map[int]bool
+	encoded   map[int]bool
+	total     int64
+	finished  int
+}
+
+func newRecordObserver() *recordObserver {
+	return &recordObserver{read: map[int]bool{}, hashed: map[int]bool{}, encoded: map[int]bool{}}
+}
+
+func (r *recordObserver) OnSegmentRead(segmentID int, size int) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.read[segmentID] = true
+	r.readBytes += size
+}
+
+func (r *recordObserver) OnSegmentHashed(segmentID int, _ time.Duration) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.hashed[segmentID] = true
+}
+
+func (r *recordObserver) OnSegmentEncoded(segmentID int, _ time.Duration) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.encoded[segmentID] = true
+}
+
+func (r *recordObserver) OnFinish(totalSize int64) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.total = totalSize
+	r.finished++
+}
+
+// check reports the notifications which differ from the ones of hashing the content of size in segmentNum
+// segments, encodedNum segments are expected to be encoded
+func (r *recordObserver) check(t *testing.T, size int, segmentNum int, encodedNum int) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.readBytes != size || r.total != int64(size) {
+		t.Errorf("read %d bytes and finished with %d bytes, expected %d", r.readBytes, r.total, size)
+	}
+	if len(r.read) != segmentNum || len(r.hashed) != segmentNum {
+		t.Errorf("%d segments read and %d segments hashed, expected %d", len(r.read), len(r.hashed), segmentNum)
+	}
+	if len(r.encoded) != encodedNum {
+		t.Errorf("%d segments encoded, expected %d", len(r.encoded), encodedNum)
+	}
+	if r.finished != 1 {
+		t.Errorf("finished %d times, expected once", r.finished)
+	}
+}
+
+func TestObserver(t *testing.T) {
+	content := newTestContent(testSegmentSize*4 + 10)
+	ctx := context.Background()
+	replicaOpt := WithRedundancyType(storagetypes.REDUNDANCY_REPLICA_TYPE)
+	expected, err := ComputeIntegrityMetaFromBuffer(content, testSegmentSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks)
+	require.NoError(t, err)
+	expectedReplica, err := ComputeIntegrityMetaFromBuffer(content, testSegmentSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks, replicaOpt)
+	require.NoError(t, err)
+
+	for _, c := range []struct {
+		name     string
+		compute  func(observer *recordObserver) (*IntegrityMeta, error)
+		expected *IntegrityMeta
+		// the segments are not encoded in the replica type
+		encodedNum int
+	}{
+		{"serial", func(observer *recordObserver) (*IntegrityMeta, error) {
+			return ComputeIntegrityMeta(ctx, bytes.NewReader(content), testSegmentSize, redundancy.DataBlocks,
+				redundancy.ParityBlocks, WithSerial(true), WithObserver(observer))
+		}, expected, 5},
+		{"parallel", func(observer *recordObserver) (*IntegrityMeta, error) {
+			return ComputeIntegrityMeta(ctx, bytes.NewReader(content), testSegmentSize, redundancy.DataBlocks,
+				redundancy.ParityBlocks, WithSerial(false), WithObserver(observer))
+		}, expected, 5},
+		{"ReaderAt", func(observer *recordObserver) (*IntegrityMeta, error) {
+			return ComputeIntegrityMetaFromReaderAt(ctx, bytes.NewReader(content), int64(len(content)),
+				testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks, WithObserver(observer))
+		}, expected, 5},
+		{"IntegrityHasher", func(observer *recordObserver) (*IntegrityMeta, error) {
+			hasher := NewHasher(testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+			hasher.Init()
+			hasher.SetObserver(observer)
+			if _, err := hasher.Write(content); err != nil {
+				return nil, err
+			}
+			return hasher.FinishMeta()
+		}, expected, 5},
+		{"replica", func(observer *recordObserver) (*IntegrityMeta, error) {
+			return ComputeIntegrityMeta(ctx, bytes.NewReader(content), testSegmentSize, redundancy.DataBlocks,
+				redundancy.ParityBlocks, WithObserver(observer), replicaOpt)
+		}, expectedReplica, 0},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			observer := newRecordObserver()
+			meta, err := c.compute(observer)
+			require.NoError(t, err)
+			if !reflect.DeepEqual(c.expected, meta) {
+				t.Errorf("meta mismatch, expected %+v, got %+v", c.expected, meta)
+			}
+			observer.check(t, len(content), 5, c.encodedNum)
+		})
+	}
+
+	// the encoding of redundancy notifies the observer
+	observer := newRecordObserver()
+	_, err = redundancy.EncodeRawSegmentWithObserver(append([]byte{}, content[:testSegmentSize]...), 3,
+		redundancy.DataBlocks, redundancy.ParityBlocks, observer)
+	require.NoError(t, err)
+	if !observer.encoded[3] {
+		t.Errorf("the encoding of segment 3 is not notified")
+	}
+}
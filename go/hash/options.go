@@ -0,0 +1,133 @@
+package hash
+
+import (
+	"runtime"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+// Option configures the way to compute the integrity hash
+type Option func(*hashOptions)
+
+type hashOptions struct {
+	isSerial            bool
+	workerNum           int
+	maxInflightSegments int
+	memoryBudget        int64
+	logger              zerolog.Logger
+	redundancyType      storagetypes.RedundancyType
+	// crc32c enables the CRC32C digest of ComputePayloadDigests
+	crc32c   bool
+	observer Observer
+}
+
+func newHashOptions(opts ...Option) *hashOptions {
+	o := &hashOptions{
+		workerNum:           defaultWorkerNum(),
+		maxInflightSegments: jobChannelSize,
+		logger:              log.Logger,
+		redundancyType:      storagetypes.REDUNDANCY_EC_TYPE,
+		observer:            NopObserver{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// defaultWorkerNum returns half of the CPU number, which should be no more than maxThreadNum and at least 1
+func defaultWorkerNum() int {
+	threadNum := runtime.NumCPU() / 2
+	if threadNum > maxThreadNum {
+		threadNum = maxThreadNum
+	}
+	if threadNum < 1 {
+		threadNum = 1
+	}
+	return threadNum
+}
+
+// inflightSegments returns the max number of segments which are being read or hashed at the same time
+func (o *hashOptions) inflightSegments(segmentSize int64) int {
+	inflight := o.maxInflightSegments
+	if o.memoryBudget > 0 && segmentSize > 0 {
+		if budgetSegments := o.memoryBudget / segmentSize; budgetSegments < int64(inflight) {
+			inflight = int(budgetSegments)
+		}
+	}
+	// at least one segment is needed to make progress
+	if inflight < 1 {
+		inflight = 1
+	}
+	return inflight
+}
+
+// WithSerial sets whether to compute the integrity hash in a serial way, the parallel way is used by default
+func WithSerial(isSerial bool) Option {
+	return func(o *hashOptions) {
+		o.isSerial = isSerial
+	}
+}
+
+// WithWorkerNum sets the number of workers to compute the hashes in parallel,
+// the default number is half of the CPU number and no more than 5
+func WithWorkerNum(workerNum int) Option {
+	return func(o *hashOptions) {
+		if workerNum > 0 {
+			o.workerNum = workerNum
+		}
+	}
+}
+
+// WithMaxInflightSegments sets the max number of segments which are read but not hashed yet, default is 100
+func WithMaxInflightSegments(segmentNum int) Option {
+	return func(o *hashOptions) {
+		if segmentNum > 0 {
+			o.maxInflightSegments = segmentNum
+		}
+	}
+}
+
+// WithMemoryBudget limits the memory of in-flight segments to the budget bytes,
+// at least one segment is kept in memory even if the budget is less than the segment size
+func WithMemoryBudget(budget int64) Option {
+	return func(o *hashOptions) {
+		o.memoryBudget = budget
+	}
+}
+
+// WithLogger sets the logger, the global zerolog logger is used by default
+func WithLogger(logger zerolog.Logger) Option {
+	return func(o *hashOptions) {
+		o.logger = logger
+	}
+}
+
+// WithRedundancyType sets the redundancy type of the object, the EC type is used by default.
+// In the replica type, every one of the dataShards+parityShards replicas stores the whole segments,
+// so the checksum list of each replica is the same as the segment checksum list.
+func WithRedundancyType(redundancyType storagetypes.RedundancyType) Option {
+	return func(o *hashOptions) {
+		o.redundancyType = redundancyType
+	}
+}
+
+// WithCRC32C enables computing the CRC32C (Castagnoli) of the payload in ComputePayloadDigests
+func WithCRC32C() Option {
+	return func(o *hashOptions) {
+		o.crc32c = true
+	}
+}
+
+// WithObserver sets the observer which receives the progress of the hashing, it is not used by BatchHasher since the
+// segment IDs of different objects are the same
+func WithObserver(observer Observer) Option {
+	return func(o *hashOptions) {
+		if observer != nil {
+			o.observer = observer
+		}
+	}
+}
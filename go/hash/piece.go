@@ -0,0 +1,105 @@
+package hash
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidPieceSize indicates the size of the piece is different from the size encoded from the segment
+	ErrInvalidPieceSize = errors.New("invalid piece size")
+	// ErrUnexpectedPiece indicates more pieces are appended than the segments of the payload
+	ErrUnexpectedPiece = errors.New("unexpected piece")
+	// ErrIncompletePieces indicates the pieces of some segments have not been appended
+	ErrIncompletePieces = errors.New("incomplete pieces")
+)
+
+// PieceIntegrityHasher computes the integrity hash of the pieces of one redundancy index, which is used by the
+// secondary SP receiving only the pieces of its own redundancy index. The pieces are appended in the segment order.
+// The redundancy index PrimaryRedundancyIndex means the pieces are the segments.
+type PieceIntegrityHasher struct {
+	redundancyIndex int
+	segmentSize     int64
+	payloadSize     int64
+	dataShards      int
+	parityShards    int
+	checksums       [][]byte
+}
+
+// NewPieceIntegrityHasher creates a PieceIntegrityHasher of the redundancy index of the object in the EC type
+func NewPieceIntegrityHasher(redundancyIndex int, segmentSize, payloadSize int64, dataShards, parityShards int,
+) (*PieceIntegrityHasher, error) {
+	if segmentSize <= 0 {
+		return nil, ErrInvalidSegmentSize
+	}
+	if payloadSize < 0 {
+		return nil, fmt.Errorf("invalid payload size: %d", payloadSize)
+	}
+	if dataShards <= 0 || parityShards < 0 {
+		return nil, fmt.Errorf("invalid EC geometry: data shards %d, parity shards %d", dataShards, parityShards)
+	}
+	if redundancyIndex < PrimaryRedundancyIndex || redundancyIndex >= dataShards+parityShards {
+		return nil, fmt.Errorf("invalid redundancy index: %d", redundancyIndex)
+	}
+	return &PieceIntegrityHasher{
+		redundancyIndex: redundancyIndex,
+		segmentSize:     segmentSize,
+		payloadSize:     payloadSize,
+		dataShards:      dataShards,
+		parityShards:    parityShards,
+		checksums:       make([][]byte, 0, SegmentCount(payloadSize, segmentSize)),
+	}, nil
+}
+
+// NextPieceSize returns the expected size of the next piece, 0 is returned if all the pieces have been appended
+func (h *PieceIntegrityHasher) NextPieceSize() int64 {
+	return h.pieceSize(len(h.checksums))
+}
+
+// AppendPiece validates the size of the piece of the next segment and computes its checksum
+func (h *PieceIntegrityHasher) AppendPiece(pieceData []byte) error {
+	segmentIndex := len(h.checksums)
+	if segmentIndex >= SegmentCount(h.payloadSize, h.segmentSize) {
+		return fmt.Errorf("%w: segment %d", ErrUnexpectedPiece, segmentIndex)
+	}
+	if expected := h.pieceSize(segmentIndex); int64(len(pieceData)) != expected {
+		return fmt.Errorf("%w: segment %d, expected %d, actual %d", ErrInvalidPieceSize, segmentIndex, expected,
+			len(pieceData))
+	}
+	h.checksums = append(h.checksums, GenerateChecksum(pieceData))
+	return nil
+}
+
+// Checksums returns the checksum list of the pieces appended
+func (h *PieceIntegrityHasher) Checksums() [][]byte {
+	return append([][]byte{}, h.checksums...)
+}
+
+// Finish returns the integrity hash of the pieces, ErrIncompletePieces is returned if the pieces of some segments
+// have not been appended
+func (h *PieceIntegrityHasher) Finish() ([]byte, error) {
+	if segmentNum := SegmentCount(h.payloadSize, h.segmentSize); len(h.checksums) != segmentNum {
+		return nil, fmt.Errorf("%w: %d of %d pieces", ErrIncompletePieces, len(h.checksums), segmentNum)
+	}
+	return GenerateIntegrityHash(h.checksums), nil
+}
+
+// Verify compares the integrity hash of the pieces with the expected one provided by the primary SP
+func (h *PieceIntegrityHasher) Verify(expectedIntegrityHash []byte) error {
+	integrityHash, err := h.Finish()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(integrityHash, expectedIntegrityHash) {
+		return fmt.Errorf("%w: redundancy index %d", ErrIntegrityMismatch, h.redundancyIndex)
+	}
+	return nil
+}
+
+func (h *PieceIntegrityHasher) pieceSize(segmentIndex int) int64 {
+	if h.redundancyIndex == PrimaryRedundancyIndex {
+		return SegmentDataSize(h.payloadSize, h.segmentSize, segmentIndex)
+	}
+	return ECPieceSize(h.payloadSize, h.segmentSize, segmentIndex, h.dataShards)
+}
@@ -0,0 +1,100 @@
+package hash
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-common/go/redundancy"
+)
+
+// newTestPieces encodes the segments of content to the pieces of every redundancy index as the primary SP
+func newTestPieces(t *testing.T, content []byte) (segments [][]byte, pieces [][][]byte) {
+	pieces = make([][][]byte, redundancy.DataBlocks+redundancy.ParityBlocks)
+	for offset := 0; offset < len(content); offset += testSegmentSize {
+		end := offset + testSegmentSize
+		if end > len(content) {
+			end = len(content)
+		}
+		segments = append(segments, content[offset:end])
+		encoded, err := redundancy.EncodeRawSegment(append([]byte{}, content[offset:end]...), redundancy.DataBlocks,
+			redundancy.ParityBlocks)
+		require.NoError(t, err)
+		for index, piece := range encoded {
+			pieces[index] = append(pieces[index], piece)
+		}
+	}
+	return segments, pieces
+}
+
+func TestPieceIntegrityHasher(t *testing.T) {
+	content := newTestContent(testSegmentSize*2 + 333)
+	payloadSize := int64(len(content))
+	meta, err := ComputeIntegrityMetaFromBuffer(content, testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+	require.NoError(t, err)
+	segments, pieces := newTestPieces(t, content)
+
+	for redundancyIndex := PrimaryRedundancyIndex; redundancyIndex < len(pieces); redundancyIndex++ {
+		pieceList := segments
+		if redundancyIndex != PrimaryRedundancyIndex {
+			pieceList = pieces[redundancyIndex]
+		}
+		expected := meta.IntegrityHashes[redundancyIndex+1]
+
+		t.Run(fmt.Sprintf("redundancy index %d", redundancyIndex), func(t *testing.T) {
+			hasher, err := NewPieceIntegrityHasher(redundancyIndex, testSegmentSize, payloadSize,
+				redundancy.DataBlocks, redundancy.ParityBlocks)
+			require.NoError(t, err)
+			for index, piece := range pieceList {
+				if size := hasher.NextPieceSize(); size != int64(len(piece)) {
+					t.Errorf("next piece size %d of piece %d, expected %d", size, index, len(piece))
+				}
+				require.NoError(t, hasher.AppendPiece(piece))
+			}
+			if size := hasher.NextPieceSize(); size != 0 {
+				t.Errorf("next piece size %d after all the pieces, expected 0", size)
+			}
+			if err := hasher.AppendPiece(pieceList[0]); !errors.Is(err, ErrUnexpectedPiece) {
+				t.Errorf("returned %v appending the extra piece, expected %v", err, ErrUnexpectedPiece)
+			}
+
+			integrityHash, err := hasher.Finish()
+			require.NoError(t, err)
+			if !bytes.Equal(expected, integrityHash) {
+				t.Errorf("integrity hash %x, expected %x", integrityHash, expected)
+			}
+			if err := hasher.Verify(expected); err != nil {
+				t.Errorf("Verify returned %v of the expected integrity hash", err)
+			}
+			if err := hasher.Verify(meta.IntegrityHashes[0][1:]); !errors.Is(err, ErrIntegrityMismatch) {
+				t.Errorf("Verify returned %v of the wrong integrity hash, expected %v", err, ErrIntegrityMismatch)
+			}
+		})
+	}
+}
+
+func TestPieceIntegrityHasherError(t *testing.T) {
+	content := newTestContent(testSegmentSize*2 + 333)
+	payloadSize := int64(len(content))
+	_, pieces := newTestPieces(t, content)
+
+	hasher, err := NewPieceIntegrityHasher(0, testSegmentSize, payloadSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks)
+	require.NoError(t, err)
+	if err := hasher.AppendPiece(pieces[0][0][1:]); !errors.Is(err, ErrInvalidPieceSize) {
+		t.Errorf("returned %v appending the short piece, expected %v", err, ErrInvalidPieceSize)
+	}
+	require.NoError(t, hasher.AppendPiece(pieces[0][0]))
+	if _, err := hasher.Finish(); !errors.Is(err, ErrIncompletePieces) {
+		t.Errorf("returned %v finishing the incomplete pieces, expected %v", err, ErrIncompletePieces)
+	}
+
+	_, err = NewPieceIntegrityHasher(redundancy.DataBlocks+redundancy.ParityBlocks, testSegmentSize, payloadSize,
+		redundancy.DataBlocks, redundancy.ParityBlocks)
+	if err == nil {
+		t.Errorf("returned nil of the redundancy index out of range, expected an error")
+	}
+}
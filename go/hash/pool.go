@@ -0,0 +1,65 @@
+package hash
+
+import (
+	"sync"
+
+	"github.com/bnb-chain/greenfield-common/go/redundancy"
+)
+
+// segmentPools caches the segment buffers by the segment size, the buffers are stored as *[]byte to avoid
+// allocating when putting them back
+var segmentPools sync.Map
+
+// encoderPools caches the erasure encoders and their shard buffers by the EC geometry
+var encoderPools sync.Map
+
+type ecGeometry struct {
+	dataShards   int
+	parityShards int
+}
+
+func segmentPool(segmentSize int64) *sync.Pool {
+	if pool, ok := segmentPools.Load(segmentSize); ok {
+		return pool.(*sync.Pool)
+	}
+	pool, _ := segmentPools.LoadOrStore(segmentSize, &sync.Pool{
+		New: func() interface{} {
+			buffer := make([]byte, segmentSize)
+			return &buffer
+		},
+	})
+	return pool.(*sync.Pool)
+}
+
+// getSegmentBuffer returns a buffer of the segment size from the pool
+func getSegmentBuffer(segmentSize int64) *[]byte {
+	return segmentPool(segmentSize).Get().(*[]byte)
+}
+
+// putSegmentBuffer puts the buffer back to the pool, the buffer should not be used after that
+func putSegmentBuffer(buffer *[]byte) {
+	*buffer = (*buffer)[:cap(*buffer)]
+	segmentPool(int64(len(*buffer))).Put(buffer)
+}
+
+func encoderPool(dataShards, parityShards int) *sync.Pool {
+	key := ecGeometry{dataShards: dataShards, parityShards: parityShards}
+	if pool, ok := encoderPools.Load(key); ok {
+		return pool.(*sync.Pool)
+	}
+	pool, _ := encoderPools.LoadOrStore(key, &sync.Pool{})
+	return pool.(*sync.Pool)
+}
+
+// getSegmentEncoder returns an erasure encoder of the EC geometry from the pool
+func getSegmentEncoder(dataShards, parityShards int) (*redundancy.RawSegmentEncoder, error) {
+	if encoder, ok := encoderPool(dataShards, parityShards).Get().(*redundancy.RawSegmentEncoder); ok {
+		return encoder, nil
+	}
+	return redundancy.NewRawSegmentEncoder(dataShards, parityShards)
+}
+
+// putSegmentEncoder puts the encoder back to the pool, the shards encoded by it should not be used after that
+func putSegmentEncoder(encoder *redundancy.RawSegmentEncoder, dataShards, parityShards int) {
+	encoderPool(dataShards, parityShards).Put(encoder)
+}
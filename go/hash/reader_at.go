@@ -0,0 +1,139 @@
+package hash
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+// ComputeIntegrityHashFromReaderAt compute the integrity hash of the first size bytes of reader.
+// Each worker reads the segments at their own offsets and computes the hashes, so the segments are read in parallel
+// and only one segment buffer is kept in memory by each worker.
+// The worker number is limited by both WithWorkerNum and the in-flight segments options, WithSerial uses one worker.
+func ComputeIntegrityHashFromReaderAt(ctx context.Context, reader io.ReaderAt, size int64, segmentSize int64,
+	dataShards, parityShards int, opts ...Option,
+) ([][]byte, int64, storagetypes.RedundancyType, error) {
+	return integrityHashResult(ComputeIntegrityMetaFromReaderAt(ctx, reader, size, segmentSize, dataShards,
+		parityShards, opts...))
+}
+
+// ComputeIntegrityMetaFromReaderAt is the same as ComputeIntegrityHashFromReaderAt, and returns the checksum lists of
+// segments and pieces as well
+func ComputeIntegrityMetaFromReaderAt(ctx context.Context, reader io.ReaderAt, size int64, segmentSize int64,
+	dataShards, parityShards int, opts ...Option,
+) (*IntegrityMeta, error) {
+	if segmentSize <= 0 {
+		return nil, ErrInvalidSegmentSize
+	}
+	if size < 0 {
+		return nil, fmt.Errorf("invalid content size: %d", size)
+	}
+
+	o := newHashOptions(opts...)
+	if err := checkRedundancyType(o.redundancyType); err != nil {
+		return nil, err
+	}
+	workerNum := o.workerNum
+	if inflightNum := o.inflightSegments(segmentSize); inflightNum < workerNum {
+		workerNum = inflightNum
+	}
+	if o.isSerial {
+		workerNum = 1
+	}
+
+	segmentNum := int((size + segmentSize - 1) / segmentSize)
+	ecShards := dataShards + parityShards
+	segChecksumList := make([][]byte, segmentNum)
+	encodeDataHash := make([][][]byte, ecShards)
+	for i := 0; i < ecShards; i++ {
+		encodeDataHash[i] = make([][]byte, segmentNum)
+	}
+
+	// the workers cancel the ctx to stop the others if failed to read or compute hash
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	jobChan := make(chan int)
+	errChan := make(chan error, 1)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < workerNum; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buffer := getSegmentBuffer(segmentSize)
+			defer putSegmentBuffer(buffer)
+			for segmentID := range jobChan {
+				if ctx.Err() != nil {
+					continue
+				}
+				err := readAndHashSegment(reader, *buffer, segmentID, size, segChecksumList, encodeDataHash,
+					dataShards, parityShards, o)
+				if err != nil {
+					select {
+					case errChan <- err:
+					default:
+					}
+					cancel()
+				}
+			}
+		}()
+	}
+
+dispatchLoop:
+	for segmentID := 0; segmentID < segmentNum; segmentID++ {
+		select {
+		case jobChan <- segmentID:
+		case <-ctx.Done():
+			break dispatchLoop
+		}
+	}
+	close(jobChan)
+	wg.Wait()
+	close(errChan)
+
+	if err := <-errChan; err != nil {
+		o.logger.Error().Msg("failed to compute integrity hash:" + err.Error())
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	o.observer.OnFinish(size)
+	return newIntegrityMeta(segChecksumList, encodeDataHash, size, o.redundancyType), nil
+}
+
+// readAndHashSegment reads the segment of segmentID into the buffer and stores the segment hash and the piece hashes
+// at the index of segmentID
+func readAndHashSegment(reader io.ReaderAt, buffer []byte, segmentID int, size int64, segChecksumList [][]byte,
+	encodeDataHash [][][]byte, dataShards, parityShards int, o *hashOptions,
+) error {
+	segmentSize := int64(len(buffer))
+	offset := int64(segmentID) * segmentSize
+	data := buffer
+	if offset+segmentSize > size {
+		data = buffer[:size-offset]
+	}
+
+	n, err := reader.ReadAt(data, offset)
+	if n < len(data) {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return fmt.Errorf("failed to read segment %d: %w", segmentID, err)
+	}
+
+	o.observer.OnSegmentRead(segmentID, len(data))
+	checksum, pieceChecksumList, err := computeSegmentHashes(SegmentInfo{SegmentID: segmentID, Data: data}, dataShards,
+		parityShards, o.redundancyType, o.observer)
+	if err != nil {
+		return err
+	}
+	segChecksumList[segmentID] = checksum
+	for index, pieceChecksum := range pieceChecksumList {
+		encodeDataHash[index][segmentID] = pieceChecksum
+	}
+	return nil
+}
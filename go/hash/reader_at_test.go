@@ -0,0 +1,81 @@
+package hash
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-common/go/redundancy"
+)
+
+func TestComputeIntegrityHashFromReaderAt(t *testing.T) {
+	for _, size := range []int{0, 1, testSegmentSize, testSegmentSize*7 + 99} {
+		content := newTestContent(size)
+		expectedHashList, expectedSize := serialIntegrityHash(t, content)
+
+		for _, c := range []struct {
+			name string
+			opts []Option
+		}{
+			{"default", nil},
+			{"serial", []Option{WithSerial(true)}},
+			{"workers", []Option{WithWorkerNum(3)}},
+			{"max inflight", []Option{WithWorkerNum(8), WithMaxInflightSegments(2)}},
+		} {
+			t.Run(fmt.Sprintf("%s %d bytes", c.name, size), func(t *testing.T) {
+				hashList, contentLen, _, err := ComputeIntegrityHashFromReaderAt(context.Background(),
+					bytes.NewReader(content), int64(size), testSegmentSize, redundancy.DataBlocks,
+					redundancy.ParityBlocks, c.opts...)
+				require.NoError(t, err)
+				if contentLen != expectedSize {
+					t.Errorf("content length %d, expected %d", contentLen, expectedSize)
+				}
+				checkHashList(t, expectedHashList, hashList)
+			})
+		}
+
+		t.Run(fmt.Sprintf("file %d bytes", size), func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "object")
+			require.NoError(t, os.WriteFile(filePath, content, 0o600))
+			hashList, contentLen, _, err := ComputerHashFromFile(filePath, testSegmentSize, redundancy.DataBlocks,
+				redundancy.ParityBlocks)
+			require.NoError(t, err)
+			if contentLen != expectedSize {
+				t.Errorf("content length %d, expected %d", contentLen, expectedSize)
+			}
+			checkHashList(t, expectedHashList, hashList)
+		})
+	}
+}
+
+func TestComputeIntegrityHashFromReaderAtError(t *testing.T) {
+	content := newTestContent(testSegmentSize * 2)
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, c := range []struct {
+		name        string
+		ctx         context.Context
+		size        int64
+		segmentSize int64
+		err         error
+	}{
+		{"size larger than content", context.Background(), int64(len(content) + 1), testSegmentSize,
+			io.ErrUnexpectedEOF},
+		{"invalid segment size", context.Background(), int64(len(content)), 0, ErrInvalidSegmentSize},
+		{"canceled", canceledCtx, int64(len(content)), testSegmentSize, context.Canceled},
+	} {
+		_, _, _, err := ComputeIntegrityHashFromReaderAt(c.ctx, bytes.NewReader(content), c.size, c.segmentSize,
+			redundancy.DataBlocks, redundancy.ParityBlocks)
+		if !errors.Is(err, c.err) {
+			t.Errorf("%s: returned %v, expected %v", c.name, err, c.err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package hash
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInvalidIntegrityMeta indicates the previous IntegrityMeta is inconsistent with the segment size, the EC geometry
+// or the last segment
+var ErrInvalidIntegrityMeta = errors.New("invalid integrity meta")
+
+// NewHasherFromMeta creates an IntegrityHasher which continues hashing after the payload described by prev, so the
+// data appended to the payload is hashed without reprocessing the segments before.
+// If the payload does not end at the segment boundary, lastSegment should be the data of the last partial segment,
+// which is hashed again together with the appended data. Otherwise lastSegment is ignored.
+func NewHasherFromMeta(prev *IntegrityMeta, segmentSize int64, dataShards, parityShards int, lastSegment []byte,
+) (*IntegrityHasher, error) {
+	if segmentSize <= 0 {
+		return nil, ErrInvalidSegmentSize
+	}
+	if err := checkRedundancyType(prev.RedundancyType); err != nil {
+		return nil, err
+	}
+	segmentNum := len(prev.SegmentChecksums)
+	if prev.ContentLength < 0 || segmentNum != SegmentCount(prev.ContentLength, segmentSize) {
+		return nil, fmt.Errorf("%w: %d segment checksums of content length %d", ErrInvalidIntegrityMeta, segmentNum,
+			prev.ContentLength)
+	}
+	if len(prev.PieceChecksums) != dataShards+parityShards {
+		return nil, fmt.Errorf("%w: %d piece checksum lists of data shards %d, parity shards %d",
+			ErrInvalidIntegrityMeta, len(prev.PieceChecksums), dataShards, parityShards)
+	}
+	for index, pieceChecksums := range prev.PieceChecksums {
+		if len(pieceChecksums) != segmentNum {
+			return nil, fmt.Errorf("%w: %d piece checksums of redundancy index %d", ErrInvalidIntegrityMeta,
+				len(pieceChecksums), index)
+		}
+	}
+
+	// the hashes of the last partial segment are dropped and computed again with the appended data
+	keepNum := segmentNum
+	var buffer []byte
+	if lastSize := prev.ContentLength % segmentSize; lastSize != 0 {
+		if int64(len(lastSegment)) != lastSize {
+			return nil, fmt.Errorf("%w: last segment size %d, expected %d", ErrInvalidIntegrityMeta, len(lastSegment),
+				lastSize)
+		}
+		if !bytes.Equal(GenerateChecksum(lastSegment), prev.SegmentChecksums[segmentNum-1]) {
+			return nil, fmt.Errorf("%w: last segment checksum mismatch", ErrInvalidIntegrityMeta)
+		}
+		keepNum--
+		buffer = append(make([]byte, 0, segmentSize), lastSegment...)
+	}
+
+	hasher := NewHasherWithRedundancyType(segmentSize, dataShards, parityShards, prev.RedundancyType)
+	hasher.Init()
+	hasher.segHashes = append(hasher.segHashes, prev.SegmentChecksums[:keepNum]...)
+	for index, pieceChecksums := range prev.PieceChecksums {
+		hasher.ecDataHashes[index] = append(hasher.ecDataHashes[index], pieceChecksums[:keepNum]...)
+	}
+	hasher.contentLen = int64(keepNum) * segmentSize
+	if buffer != nil {
+		hasher.buffer = buffer
+	}
+	return hasher, nil
+}
+
+// ComputeAppendedIntegrityMeta returns the integrity meta of the payload described by prev followed by the data of
+// reader, the result is the same as computing the whole payload again. See NewHasherFromMeta for lastSegment.
+func ComputeAppendedIntegrityMeta(prev *IntegrityMeta, segmentSize int64, dataShards, parityShards int,
+	lastSegment []byte, reader io.Reader,
+) (*IntegrityMeta, error) {
+	hasher, err := NewHasherFromMeta(prev, segmentSize, dataShards, parityShards, lastSegment)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = hasher.ReadFrom(reader); err != nil {
+		return nil, err
+	}
+	return hasher.FinishMeta()
+}
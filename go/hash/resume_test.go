@@ -0,0 +1,68 @@
+package hash
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-common/go/redundancy"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+func TestComputeAppendedIntegrityMeta(t *testing.T) {
+	content := newTestContent(testSegmentSize*5 + 123)
+
+	for _, redundancyType := range []storagetypes.RedundancyType{storagetypes.REDUNDANCY_EC_TYPE,
+		storagetypes.REDUNDANCY_REPLICA_TYPE} {
+		expected, err := ComputeIntegrityMetaFromBuffer(content, testSegmentSize, redundancy.DataBlocks,
+			redundancy.ParityBlocks, WithRedundancyType(redundancyType))
+		require.NoError(t, err)
+
+		// split at the segment boundary, inside a segment and at the beginning
+		for _, prevSize := range []int{0, testSegmentSize * 2, testSegmentSize*2 + 500, len(content) - 1} {
+			t.Run(fmt.Sprintf("%s %d bytes", redundancyType, prevSize), func(t *testing.T) {
+				prev, err := ComputeIntegrityMetaFromBuffer(content[:prevSize], testSegmentSize,
+					redundancy.DataBlocks, redundancy.ParityBlocks, WithRedundancyType(redundancyType))
+				require.NoError(t, err)
+				lastSegment := content[prevSize-prevSize%testSegmentSize : prevSize]
+
+				meta, err := ComputeAppendedIntegrityMeta(prev, testSegmentSize, redundancy.DataBlocks,
+					redundancy.ParityBlocks, lastSegment, bytes.NewReader(content[prevSize:]))
+				require.NoError(t, err)
+				if !reflect.DeepEqual(expected, meta) {
+					t.Errorf("meta mismatch, expected %+v, got %+v", expected, meta)
+				}
+			})
+		}
+	}
+}
+
+func TestNewHasherFromMetaError(t *testing.T) {
+	content := newTestContent(testSegmentSize*2 + 10)
+	prev, err := ComputeIntegrityMetaFromBuffer(content[:testSegmentSize+10], testSegmentSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks)
+	require.NoError(t, err)
+
+	for _, c := range []struct {
+		name         string
+		segmentSize  int64
+		parityShards int
+		lastSegment  []byte
+	}{
+		{"short last segment", testSegmentSize, redundancy.ParityBlocks, content[testSegmentSize : testSegmentSize+9]},
+		{"wrong last segment", testSegmentSize, redundancy.ParityBlocks,
+			content[testSegmentSize+1 : testSegmentSize+11]},
+		{"other segment size", testSegmentSize * 2, redundancy.ParityBlocks, nil},
+		{"other parity shards", testSegmentSize, redundancy.ParityBlocks + 1,
+			content[testSegmentSize : testSegmentSize+10]},
+	} {
+		_, err := NewHasherFromMeta(prev, c.segmentSize, redundancy.DataBlocks, c.parityShards, c.lastSegment)
+		if !errors.Is(err, ErrInvalidIntegrityMeta) {
+			t.Errorf("%s: returned %v, expected %v", c.name, err, ErrInvalidIntegrityMeta)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package hash
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrInvalidSegmentSize indicates the segment size is not positive
+var ErrInvalidSegmentSize = errors.New("segment size should be positive")
+
+// SegmentReader splits the data of reader into segments, every segment is filled up to the segment size
+// except the last one, no matter how many bytes are returned by each Read of the reader
+type SegmentReader struct {
+	reader      io.Reader
+	segmentSize int64
+	segmentID   int
+	err         error
+}
+
+// NewSegmentReader creates a SegmentReader which reads the segments of segmentSize from reader
+func NewSegmentReader(reader io.Reader, segmentSize int64) *SegmentReader {
+	s := &SegmentReader{
+		reader:      reader,
+		segmentSize: segmentSize,
+	}
+	if segmentSize <= 0 {
+		s.err = ErrInvalidSegmentSize
+	}
+	return s
+}
+
+// Next returns the next segment, io.EOF is returned if there is no more data.
+// If the reader returns an error other than io.EOF, the data read before the error is returned with the error.
+func (s *SegmentReader) Next() (SegmentInfo, error) {
+	if s.err != nil {
+		return SegmentInfo{SegmentID: s.segmentID}, s.err
+	}
+	return s.NextInto(make([]byte, s.segmentSize))
+}
+
+// NextInto is the same as Next, and reads the segment into the buffer instead of allocating a new one,
+// the length of buffer should be at least the segment size. The data of the segment is only valid until the buffer
+// is reused.
+func (s *SegmentReader) NextInto(buffer []byte) (SegmentInfo, error) {
+	if s.err != nil {
+		return SegmentInfo{SegmentID: s.segmentID}, s.err
+	}
+	if int64(len(buffer)) < s.segmentSize {
+		return SegmentInfo{SegmentID: s.segmentID}, io.ErrShortBuffer
+	}
+
+	seg := buffer[:s.segmentSize]
+	n, err := io.ReadFull(s.reader, seg)
+	switch err {
+	case nil:
+	case io.EOF:
+		s.err = io.EOF
+		return SegmentInfo{SegmentID: s.segmentID}, io.EOF
+	case io.ErrUnexpectedEOF:
+		// the last segment is not full, the next call returns io.EOF
+		s.err = io.EOF
+	default:
+		s.err = err
+		return SegmentInfo{SegmentID: s.segmentID, Data: seg[:n]}, err
+	}
+
+	segment := SegmentInfo{SegmentID: s.segmentID, Data: seg[:n]}
+	s.segmentID++
+	return segment, nil
+}
+
+// SegmentCount returns the number of segments of the payload
+func SegmentCount(payloadSize, segmentSize int64) int {
+	if payloadSize <= 0 || segmentSize <= 0 {
+		return 0
+	}
+	return int((payloadSize + segmentSize - 1) / segmentSize)
+}
+
+// SegmentDataSize returns the size of the segment at segmentIndex, only the last segment may be smaller than
+// segmentSize
+func SegmentDataSize(payloadSize, segmentSize int64, segmentIndex int) int64 {
+	if segmentIndex < 0 || segmentIndex >= SegmentCount(payloadSize, segmentSize) {
+		return 0
+	}
+	if remain := payloadSize - int64(segmentIndex)*segmentSize; remain < segmentSize {
+		return remain
+	}
+	return segmentSize
+}
+
+// ECPieceSize returns the size of the EC pieces encoded from the segment at segmentIndex, the segment is split
+// into dataShards pieces of the same size and the last data piece is padded with zeros
+func ECPieceSize(payloadSize, segmentSize int64, segmentIndex int, dataShards int) int64 {
+	if dataShards <= 0 {
+		return 0
+	}
+	return (SegmentDataSize(payloadSize, segmentSize, segmentIndex) + int64(dataShards) - 1) / int64(dataShards)
+}
@@ -0,0 +1,142 @@
+package hash
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-common/go/redundancy"
+)
+
+// readerCase creates the reader of the content by newReader
+type readerCase struct {
+	name      string
+	newReader func(content []byte) io.Reader
+}
+
+// shortReaders are the readers which return the content by short reads
+var shortReaders = []readerCase{
+	{"OneByteReader", func(content []byte) io.Reader { return iotest.OneByteReader(bytes.NewReader(content)) }},
+	{"HalfReader", func(content []byte) io.Reader { return iotest.HalfReader(bytes.NewReader(content)) }},
+	{"DataErrReader", func(content []byte) io.Reader { return iotest.DataErrReader(bytes.NewReader(content)) }},
+}
+
+func TestSegmentReader(t *testing.T) {
+	content := newTestContent(testSegmentSize*3 + 100)
+	readers := append([]readerCase{{"Reader", func(content []byte) io.Reader { return bytes.NewReader(content) }}},
+		shortReaders...)
+
+	for _, c := range readers {
+		t.Run(c.name, func(t *testing.T) {
+			segReader := NewSegmentReader(c.newReader(content), testSegmentSize)
+			var segments [][]byte
+			for {
+				seg, err := segReader.Next()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				if seg.SegmentID != len(segments) {
+					t.Errorf("segment ID %d, expected %d", seg.SegmentID, len(segments))
+				}
+				segments = append(segments, seg.Data)
+			}
+			require.Len(t, segments, 4)
+			for index, seg := range segments[:3] {
+				if len(seg) != testSegmentSize {
+					t.Errorf("segment %d of %d bytes, expected %d", index, len(seg), testSegmentSize)
+				}
+			}
+			if !bytes.Equal(content, bytes.Join(segments, nil)) {
+				t.Errorf("the segments are not the content")
+			}
+
+			// io.EOF should be returned repeatedly after all the data is read
+			if _, err := segReader.Next(); err != io.EOF {
+				t.Errorf("returned %v after all the data is read, expected io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestSegmentReaderError(t *testing.T) {
+	content := newTestContent(testSegmentSize)
+	if _, err := NewSegmentReader(bytes.NewReader(content), 0).Next(); !errors.Is(err, ErrInvalidSegmentSize) {
+		t.Errorf("returned %v of segment size 0, expected %v", err, ErrInvalidSegmentSize)
+	}
+
+	// the data read before the error is returned with the error
+	readErr := errors.New("mock read error")
+	segReader := NewSegmentReader(io.MultiReader(bytes.NewReader(content[:10]), iotest.ErrReader(readErr)),
+		testSegmentSize)
+	seg, err := segReader.Next()
+	if !errors.Is(err, readErr) {
+		t.Errorf("returned %v, expected %v", err, readErr)
+	}
+	if !bytes.Equal(content[:10], seg.Data) {
+		t.Errorf("returned %d bytes with the error, expected the 10 bytes read", len(seg.Data))
+	}
+}
+
+// TestSegmentReaderNextInto checks NextInto reads the segments into the buffer
+func TestSegmentReaderNextInto(t *testing.T) {
+	content := newTestContent(testSegmentSize*3 + 100)
+	segReader := NewSegmentReader(bytes.NewReader(content), testSegmentSize)
+	if _, err := segReader.NextInto(make([]byte, testSegmentSize-1)); !errors.Is(err, io.ErrShortBuffer) {
+		t.Errorf("returned %v of the short buffer, expected %v", err, io.ErrShortBuffer)
+	}
+	buffer := make([]byte, testSegmentSize)
+	for offset := 0; offset < len(content); offset += testSegmentSize {
+		seg, err := segReader.NextInto(buffer)
+		require.NoError(t, err)
+		if &seg.Data[0] != &buffer[0] {
+			t.Errorf("segment at %d is not read into the buffer", offset)
+		}
+		if !bytes.Equal(content[offset:offset+len(seg.Data)], seg.Data) {
+			t.Errorf("segment at %d mismatch", offset)
+		}
+	}
+	if _, err := segReader.NextInto(buffer); err != io.EOF {
+		t.Errorf("returned %v after all the data is read, expected io.EOF", err)
+	}
+}
+
+// TestHashWithShortReads compare the hash results of readers which return short reads with the results of the full
+// reads, all the hashing paths should produce the same segment boundaries
+func TestHashWithShortReads(t *testing.T) {
+	content := newTestContent(testSegmentSize*9 + 500)
+	expectedHashList, expectedSize := serialIntegrityHash(t, content)
+
+	for _, c := range shortReaders {
+		for _, isSerial := range []bool{true, false} {
+			t.Run(fmt.Sprintf("%s serial %t", c.name, isSerial), func(t *testing.T) {
+				hashList, size, _, err := ComputeIntegrityHashWithContext(context.Background(), c.newReader(content),
+					testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks, isSerial)
+				require.NoError(t, err)
+				if size != expectedSize {
+					t.Errorf("content length %d, expected %d", size, expectedSize)
+				}
+				checkHashList(t, expectedHashList, hashList)
+			})
+		}
+
+		t.Run(c.name+" IntegrityHasher", func(t *testing.T) {
+			hasher := NewHasher(testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+			hasher.Init()
+			// write some data first to make the buffer of hasher not empty
+			require.NoError(t, hasher.Append(content[:testSegmentSize/3]))
+			n, err := hasher.ReadFrom(c.newReader(content[testSegmentSize/3:]))
+			require.NoError(t, err)
+			if expected := int64(len(content) - testSegmentSize/3); n != expected {
+				t.Errorf("read %d bytes, expected %d", n, expected)
+			}
+			checkHasherResult(t, hasher, expectedHashList, expectedSize)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package hash
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+var (
+	// ErrIntegrityMismatch indicates the integrity hashes of the object are different from the expected checksums
+	ErrIntegrityMismatch = errors.New("integrity hash mismatch")
+	// ErrVerifierClosed indicates the data is written to a closed ObjectVerifier
+	ErrVerifierClosed = errors.New("object verifier is closed")
+)
+
+// IntegrityMismatchError reports which integrity hashes of the object mismatch, it wraps ErrIntegrityMismatch
+type IntegrityMismatchError struct {
+	// PrimaryMismatch is true if the integrity hash of the segments stored by the primary SP mismatches
+	PrimaryMismatch bool
+	// SecondaryIndices is the redundancy indices of the secondary SPs whose integrity hashes mismatch
+	SecondaryIndices []int
+}
+
+func (e *IntegrityMismatchError) Error() string {
+	var mismatches []string
+	if e.PrimaryMismatch {
+		mismatches = append(mismatches, "primary")
+	}
+	if len(e.SecondaryIndices) > 0 {
+		mismatches = append(mismatches, fmt.Sprintf("secondary redundancy indices %v", e.SecondaryIndices))
+	}
+	return ErrIntegrityMismatch.Error() + ": " + strings.Join(mismatches, ", ")
+}
+
+func (e *IntegrityMismatchError) Unwrap() error {
+	return ErrIntegrityMismatch
+}
+
+// ObjectVerifier verifies the object written to it against the expected checksums stored on chain, the checksums
+// are the integrity hash of segments followed by the integrity hashes of the pieces of each redundancy index
+type ObjectVerifier struct {
+	hasher   *IntegrityHasher
+	expected [][]byte
+	closed   bool
+	err      error
+}
+
+// NewObjectVerifier creates an ObjectVerifier of the EC type
+func NewObjectVerifier(expectedChecksums [][]byte, segmentSize int64, dataShards, parityShards int,
+) (*ObjectVerifier, error) {
+	return NewObjectVerifierWithRedundancyType(expectedChecksums, segmentSize, dataShards, parityShards,
+		storagetypes.REDUNDANCY_EC_TYPE)
+}
+
+// NewObjectVerifierWithRedundancyType creates an ObjectVerifier of the redundancy type
+func NewObjectVerifierWithRedundancyType(expectedChecksums [][]byte, segmentSize int64, dataShards, parityShards int,
+	redundancyType storagetypes.RedundancyType,
+) (*ObjectVerifier, error) {
+	if segmentSize <= 0 {
+		return nil, ErrInvalidSegmentSize
+	}
+	if err := checkRedundancyType(redundancyType); err != nil {
+		return nil, err
+	}
+	if dataShards <= 0 || parityShards < 0 {
+		return nil, fmt.Errorf("invalid EC geometry: data shards %d, parity shards %d", dataShards, parityShards)
+	}
+	if len(expectedChecksums) != dataShards+parityShards+1 {
+		return nil, fmt.Errorf("invalid expected checksums number: %d, data shards %d, parity shards %d",
+			len(expectedChecksums), dataShards, parityShards)
+	}
+
+	hasher := NewHasherWithRedundancyType(segmentSize, dataShards, parityShards, redundancyType)
+	hasher.Init()
+	return &ObjectVerifier{
+		hasher:   hasher,
+		expected: expectedChecksums,
+	}, nil
+}
+
+// Write implements io.Writer, the data is hashed by segments
+func (v *ObjectVerifier) Write(data []byte) (int, error) {
+	if v.closed {
+		return 0, ErrVerifierClosed
+	}
+	return v.hasher.Write(data)
+}
+
+// Close implements io.Closer, it hashes the remaining data and compares the integrity hashes with the expected
+// checksums. An *IntegrityMismatchError is returned if any of the integrity hashes mismatches.
+func (v *ObjectVerifier) Close() error {
+	if v.closed {
+		return v.err
+	}
+	v.closed = true
+
+	integrityHashes, _, _, err := v.hasher.Finish()
+	if err != nil {
+		v.err = err
+		return err
+	}
+	mismatch := &IntegrityMismatchError{}
+	for index, integrityHash := range integrityHashes {
+		if bytes.Equal(integrityHash, v.expected[index]) {
+			continue
+		}
+		if index == 0 {
+			mismatch.PrimaryMismatch = true
+		} else {
+			mismatch.SecondaryIndices = append(mismatch.SecondaryIndices, index-1)
+		}
+	}
+	if mismatch.PrimaryMismatch || len(mismatch.SecondaryIndices) > 0 {
+		v.err = mismatch
+	}
+	return v.err
+}
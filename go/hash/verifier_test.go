@@ -0,0 +1,92 @@
+package hash
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-common/go/redundancy"
+)
+
+func TestObjectVerifier(t *testing.T) {
+	content := newTestContent(testSegmentSize*3 + 100)
+	expected, _ := serialIntegrityHash(t, content)
+
+	// the checksums of the primary and the secondary of redundancy index 2 are wrong
+	wrongChecksums := append([][]byte{}, expected...)
+	wrongChecksums[0] = GenerateChecksum([]byte("primary"))
+	wrongChecksums[3] = GenerateChecksum([]byte("secondary"))
+	allSecondaries := make([]int, redundancy.DataBlocks+redundancy.ParityBlocks)
+	for i := range allSecondaries {
+		allSecondaries[i] = i
+	}
+
+	for _, c := range []struct {
+		name      string
+		checksums [][]byte
+		content   []byte
+		// the verifier matches all the integrity hashes if mismatch is nil
+		mismatch *IntegrityMismatchError
+	}{
+		{"match", expected, content, nil},
+		{"wrong checksums", wrongChecksums, content,
+			&IntegrityMismatchError{PrimaryMismatch: true, SecondaryIndices: []int{2}}},
+		// the corrupted content mismatches all the integrity hashes
+		{"corrupted content", expected, content[1:],
+			&IntegrityMismatchError{PrimaryMismatch: true, SecondaryIndices: allSecondaries}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			verifier, err := NewObjectVerifier(c.checksums, testSegmentSize, redundancy.DataBlocks,
+				redundancy.ParityBlocks)
+			require.NoError(t, err)
+			_, err = io.Copy(verifier, bytes.NewReader(c.content))
+			require.NoError(t, err)
+			err = verifier.Close()
+			if c.mismatch == nil {
+				if err != nil {
+					t.Errorf("returned %v, expected nil", err)
+				}
+			} else {
+				var mismatch *IntegrityMismatchError
+				if !errors.Is(err, ErrIntegrityMismatch) || !errors.As(err, &mismatch) {
+					t.Fatalf("returned %v, expected %v", err, ErrIntegrityMismatch)
+				}
+				if mismatch.PrimaryMismatch != c.mismatch.PrimaryMismatch ||
+					!reflect.DeepEqual(mismatch.SecondaryIndices, c.mismatch.SecondaryIndices) {
+					t.Errorf("mismatch %+v, expected %+v", mismatch, c.mismatch)
+				}
+			}
+			// the result is kept after closed
+			if again := verifier.Close(); !reflect.DeepEqual(err, again) {
+				t.Errorf("returned %v closing again, expected %v", again, err)
+			}
+			if _, err := verifier.Write(c.content); !errors.Is(err, ErrVerifierClosed) {
+				t.Errorf("returned %v writing after closed, expected %v", err, ErrVerifierClosed)
+			}
+		})
+	}
+}
+
+func TestNewObjectVerifierError(t *testing.T) {
+	expected, _ := serialIntegrityHash(t, newTestContent(testSegmentSize))
+	for _, c := range []struct {
+		name         string
+		checksums    [][]byte
+		dataShards   int
+		parityShards int
+	}{
+		{"short checksums", expected[1:], redundancy.DataBlocks, redundancy.ParityBlocks},
+		// the shard numbers are validated even if their sum matches the checksums number
+		{"negative data shards", expected[:2], -1, 2},
+		{"negative parity shards", expected[:2], 2, -1},
+		{"no shards", expected[:1], 0, 0},
+	} {
+		if _, err := NewObjectVerifier(c.checksums, testSegmentSize, c.dataShards, c.parityShards); err == nil {
+			t.Errorf("%s: returned nil, expected an error", c.name)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package hash
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// ErrSegmentChecksumMismatch indicates the data of the segment does not match the expected checksum
+	ErrSegmentChecksumMismatch = errors.New("segment checksum mismatch")
+	// ErrUnexpectedSegment indicates there is more data than the expected segments
+	ErrUnexpectedSegment = errors.New("unexpected segment")
+)
+
+// SegmentChecksumError is the failure of verifying the segment at SegmentIndex, Err is ErrSegmentChecksumMismatch,
+// ErrUnexpectedSegment or io.ErrUnexpectedEOF if the segment is missing
+type SegmentChecksumError struct {
+	SegmentIndex int
+	Err          error
+}
+
+func (e *SegmentChecksumError) Error() string {
+	return fmt.Sprintf("segment %d: %s", e.SegmentIndex, e.Err.Error())
+}
+
+func (e *SegmentChecksumError) Unwrap() error {
+	return e.Err
+}
+
+// VerifyingReader reads the segments from the underlying reader and verifies each of them against the expected
+// segment checksum before returning its data, so the corrupted data is never handed to the caller. The segments are
+// read into a pooled buffer, which is put back once the reader returns an error or io.EOF.
+type VerifyingReader struct {
+	segReader    *SegmentReader
+	segmentSize  int64
+	buffer       *[]byte
+	checksums    [][]byte
+	segmentIndex int
+	pending      []byte
+	err          error
+}
+
+// NewVerifyingReader creates a VerifyingReader of the whole object, the reader should contain all the segments of
+// segmentChecksums
+func NewVerifyingReader(reader io.Reader, segmentSize int64, segmentChecksums [][]byte) *VerifyingReader {
+	return NewVerifyingReaderWithOffset(reader, segmentSize, segmentChecksums, 0)
+}
+
+// NewVerifyingReaderWithOffset creates a VerifyingReader of the ranged download which starts at the offset of the
+// object and reads to the end. The offset should be aligned to the segment size.
+func NewVerifyingReaderWithOffset(reader io.Reader, segmentSize int64, segmentChecksums [][]byte, offset int64,
+) *VerifyingReader {
+	v := &VerifyingReader{
+		segReader:   NewSegmentReader(reader, segmentSize),
+		segmentSize: segmentSize,
+		checksums:   segmentChecksums,
+	}
+	if segmentSize <= 0 {
+		v.err = ErrInvalidSegmentSize
+		return v
+	}
+	if offset < 0 || offset%segmentSize != 0 || offset/segmentSize > int64(len(segmentChecksums)) {
+		v.err = fmt.Errorf("invalid offset %d of segment size %d", offset, segmentSize)
+		return v
+	}
+	v.segmentIndex = int(offset / segmentSize)
+	return v
+}
+
+// Read implements io.Reader, the data of a segment is returned only after the whole segment is verified
+func (v *VerifyingReader) Read(p []byte) (int, error) {
+	if len(v.pending) == 0 && v.err == nil {
+		v.pending, v.err = v.nextSegment()
+	}
+	if len(v.pending) == 0 {
+		// the pending data has been consumed, the buffer is no longer referred
+		if v.buffer != nil {
+			putSegmentBuffer(v.buffer)
+			v.buffer = nil
+		}
+		return 0, v.err
+	}
+	n := copy(p, v.pending)
+	v.pending = v.pending[n:]
+	return n, nil
+}
+
+// nextSegment reads and verifies the next segment, the returned data refers to the buffer of v and is valid until
+// the next call
+func (v *VerifyingReader) nextSegment() ([]byte, error) {
+	if v.buffer == nil {
+		v.buffer = getSegmentBuffer(v.segmentSize)
+	}
+	seg, err := v.segReader.NextInto(*v.buffer)
+	if err == io.EOF {
+		if v.segmentIndex < len(v.checksums) {
+			return nil, &SegmentChecksumError{SegmentIndex: v.segmentIndex, Err: io.ErrUnexpectedEOF}
+		}
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index := v.segmentIndex
+	if index >= len(v.checksums) {
+		return nil, &SegmentChecksumError{SegmentIndex: index, Err: ErrUnexpectedSegment}
+	}
+	if !bytes.Equal(GenerateChecksum(seg.Data), v.checksums[index]) {
+		return nil, &SegmentChecksumError{SegmentIndex: index, Err: ErrSegmentChecksumMismatch}
+	}
+	v.segmentIndex++
+	return seg.Data, nil
+}
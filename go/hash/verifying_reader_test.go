@@ -0,0 +1,90 @@
+package hash
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-common/go/redundancy"
+)
+
+func TestVerifyingReader(t *testing.T) {
+	content := newTestContent(testSegmentSize*4 + 10)
+	meta, err := ComputeIntegrityMetaFromBuffer(content, testSegmentSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks)
+	require.NoError(t, err)
+	checksums := meta.SegmentChecksums
+
+	// the data is the same no matter how it is read
+	if err := iotest.TestReader(NewVerifyingReader(bytes.NewReader(content), testSegmentSize, checksums),
+		content); err != nil {
+		t.Errorf("TestReader: %v", err)
+	}
+	for _, c := range []struct {
+		name     string
+		reader   io.Reader
+		offset   int64
+		expected []byte
+	}{
+		{"OneByteReader", iotest.OneByteReader(bytes.NewReader(content)), 0, content},
+		// ranged download from the segment 2
+		{"segment 2", bytes.NewReader(content[2*testSegmentSize:]), 2 * testSegmentSize,
+			content[2*testSegmentSize:]},
+	} {
+		data, err := io.ReadAll(NewVerifyingReaderWithOffset(c.reader, testSegmentSize, checksums, c.offset))
+		require.NoError(t, err, c.name)
+		if !bytes.Equal(c.expected, data) {
+			t.Errorf("%s: returned %d bytes differing from the content", c.name, len(data))
+		}
+	}
+}
+
+func TestVerifyingReaderError(t *testing.T) {
+	content := newTestContent(testSegmentSize*4 + 10)
+	meta, err := ComputeIntegrityMetaFromBuffer(content, testSegmentSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks)
+	require.NoError(t, err)
+	checksums := meta.SegmentChecksums
+	corrupted := append([]byte{}, content...)
+	corrupted[2*testSegmentSize+1]++
+
+	for _, c := range []struct {
+		name      string
+		reader    io.Reader
+		offset    int64
+		checksums [][]byte
+		err       error
+		// the segment index of the SegmentChecksumError, -1 if the error is not a SegmentChecksumError
+		segmentIndex int
+		// the data returned before the error
+		data []byte
+	}{
+		// the corrupted segment is not returned
+		{"corrupted", bytes.NewReader(corrupted), 0, checksums, ErrSegmentChecksumMismatch, 2,
+			content[:2*testSegmentSize]},
+		{"missing data", bytes.NewReader(content[:3*testSegmentSize]), 0, checksums, io.ErrUnexpectedEOF, 3,
+			content[:3*testSegmentSize]},
+		{"extra data", bytes.NewReader(append(append([]byte{}, content...), 0)), 0, checksums,
+			ErrSegmentChecksumMismatch, 4, content[:4*testSegmentSize]},
+		{"extra segment", bytes.NewReader(content), 0, checksums[:4], ErrUnexpectedSegment, -1,
+			content[:4*testSegmentSize]},
+		{"offset not at segment boundary", bytes.NewReader(content[10:]), 10, checksums, nil, -1, nil},
+	} {
+		data, err := io.ReadAll(NewVerifyingReaderWithOffset(c.reader, testSegmentSize, c.checksums, c.offset))
+		if err == nil || (c.err != nil && !errors.Is(err, c.err)) {
+			t.Errorf("%s: returned %v, expected %v", c.name, err, c.err)
+			continue
+		}
+		var segErr *SegmentChecksumError
+		if c.segmentIndex >= 0 && (!errors.As(err, &segErr) || segErr.SegmentIndex != c.segmentIndex) {
+			t.Errorf("%s: returned %v, expected the error of segment %d", c.name, err, c.segmentIndex)
+		}
+		if c.data != nil && !bytes.Equal(c.data, data) {
+			t.Errorf("%s: returned %d bytes before the error, expected %d", c.name, len(data), len(c.data))
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package http
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AuthSignatureField is the field of the signature in the Authorization header
+const AuthSignatureField = "Signature"
+
+var (
+	// ErrMalformedAuthHeader indicates the Authorization header is not in the format of "Algorithm, Key=Value, ..."
+	ErrMalformedAuthHeader = errors.New("malformed authorization header")
+	// ErrUnknownAuthAlgorithm indicates the algorithm is none of the auth type constants
+	ErrUnknownAuthAlgorithm = errors.New("unknown authorization algorithm")
+	// ErrDuplicateAuthField indicates a field appears more than once in the Authorization header
+	ErrDuplicateAuthField = errors.New("duplicate authorization field")
+	// ErrInvalidAuthSignature indicates the signature is missing or not in hex
+	ErrInvalidAuthSignature = errors.New("invalid authorization signature")
+	// ErrInvalidAuthField indicates the key or the value of a field can not be carried by the Authorization header
+	ErrInvalidAuthField = errors.New("invalid authorization field")
+)
+
+var authAlgorithms = map[string]struct{}{
+	Gnfd1Ecdsa:           {},
+	Gnfd1Eddsa:           {},
+	Gnfd2Eddsa:           {},
+	Gnfd1EthPersonalSign: {},
+}
+
+// AuthHeaderError is the error of parsing the Authorization header, Field is the field failed to be parsed, or
+// empty if the error is not of a field
+type AuthHeaderError struct {
+	Field string
+	Err   error
+}
+
+func (e *AuthHeaderError) Error() string {
+	if e.Field == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("field %s: %s", e.Field, e.Err.Error())
+}
+
+func (e *AuthHeaderError) Unwrap() error {
+	return e.Err
+}
+
+// AuthHeader is the Authorization header, such as "GNFD1-ECDSA, Signature=<hex>", Algorithm is one of the auth
+// type constants and Fields are the extra fields other than the signature
+type AuthHeader struct {
+	Algorithm string
+	Signature []byte
+	Fields    map[string]string
+}
+
+// Validate checks the AuthHeader can be parsed back by ParseAuthHeader, the algorithm must be one of the auth type
+// constants, the signature must not be empty, and the keys and the values of the fields must not be empty or contain
+// the separators "," and "=", the spaces around them or the line breaks
+func (h *AuthHeader) Validate() error {
+	if _, ok := authAlgorithms[h.Algorithm]; !ok {
+		return &AuthHeaderError{Err: fmt.Errorf("%w: %s", ErrUnknownAuthAlgorithm, h.Algorithm)}
+	}
+	if len(h.Signature) == 0 {
+		return &AuthHeaderError{Field: AuthSignatureField, Err: fmt.Errorf("%w: missing", ErrInvalidAuthSignature)}
+	}
+	for key, val := range h.Fields {
+		if key == AuthSignatureField {
+			return &AuthHeaderError{Field: key, Err: ErrDuplicateAuthField}
+		}
+		if err := checkAuthToken(key); err != nil {
+			return &AuthHeaderError{Field: key, Err: fmt.Errorf("%w: key %q", err, key)}
+		}
+		if err := checkAuthToken(val); err != nil {
+			return &AuthHeaderError{Field: key, Err: fmt.Errorf("%w: value %q", err, val)}
+		}
+	}
+	return nil
+}
+
+// Build validates the AuthHeader and returns the value of the Authorization header
+func (h *AuthHeader) Build() (string, error) {
+	if err := h.Validate(); err != nil {
+		return "", err
+	}
+	return h.String(), nil
+}
+
+// String returns the value of the Authorization header, the extra fields are sorted by key after the signature.
+// The AuthHeader should be validated, or Build should be used instead.
+func (h *AuthHeader) String() string {
+	var content strings.Builder
+	content.WriteString(h.Algorithm)
+	content.WriteString(", " + AuthSignatureField + "=" + hex.EncodeToString(h.Signature))
+	keys := make([]string, 0, len(h.Fields))
+	for key := range h.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		content.WriteString(", " + key + "=" + h.Fields[key])
+	}
+	return content.String()
+}
+
+// checkAuthToken checks the key or the value of a field is not empty and contains no separator, the spaces around
+// it or the line breaks
+func checkAuthToken(token string) error {
+	if token == "" || strings.TrimSpace(token) != token || strings.ContainsAny(token, ",=\r\n") {
+		return ErrInvalidAuthField
+	}
+	return nil
+}
+
+// ParseAuthHeader parses the value of the Authorization header strictly, it rejects the unknown algorithm, the
+// duplicate fields, the values with "=" and the signature not in hex, the returned error is an *AuthHeaderError
+func ParseAuthHeader(value string) (*AuthHeader, error) {
+	parts := strings.Split(value, ",")
+	algorithm := strings.TrimSpace(parts[0])
+	if algorithm == "" {
+		return nil, &AuthHeaderError{Err: fmt.Errorf("%w: empty algorithm", ErrMalformedAuthHeader)}
+	}
+	if _, ok := authAlgorithms[algorithm]; !ok {
+		return nil, &AuthHeaderError{Err: fmt.Errorf("%w: %s", ErrUnknownAuthAlgorithm, algorithm)}
+	}
+
+	header := &AuthHeader{Algorithm: algorithm, Fields: make(map[string]string)}
+	hasSignature := false
+	for _, part := range parts[1:] {
+		key, val, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found || checkAuthToken(key) != nil {
+			return nil, &AuthHeaderError{Err: fmt.Errorf("%w: %q is not a key=value field", ErrMalformedAuthHeader,
+				strings.TrimSpace(part))}
+		}
+		if key == AuthSignatureField {
+			if hasSignature {
+				return nil, &AuthHeaderError{Field: key, Err: ErrDuplicateAuthField}
+			}
+			// the signature of Gnfd1EthPersonalSign is 0x prefixed by the wallets
+			signature, err := hex.DecodeString(strings.TrimPrefix(val, "0x"))
+			if err != nil || len(signature) == 0 {
+				return nil, &AuthHeaderError{Field: key, Err: fmt.Errorf("%w: %q", ErrInvalidAuthSignature, val)}
+			}
+			header.Signature = signature
+			hasSignature = true
+			continue
+		}
+		if _, ok := header.Fields[key]; ok {
+			return nil, &AuthHeaderError{Field: key, Err: ErrDuplicateAuthField}
+		}
+		if err := checkAuthToken(val); err != nil {
+			return nil, &AuthHeaderError{Field: key, Err: fmt.Errorf("%w: value %q", err, val)}
+		}
+		header.Fields[key] = val
+	}
+	if !hasSignature {
+		return nil, &AuthHeaderError{Field: AuthSignatureField, Err: fmt.Errorf("%w: missing", ErrInvalidAuthSignature)}
+	}
+	return header, nil
+}
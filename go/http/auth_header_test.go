@@ -0,0 +1,127 @@
+package http
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthHeader(t *testing.T) {
+	header := &AuthHeader{Algorithm: Gnfd1Eddsa, Signature: []byte{0x01, 0xab}, Fields: map[string]string{
+		"Version": "1",
+		"Domain":  "https://greenfield.example",
+	}}
+	value, err := header.Build()
+	assert.Nil(t, err)
+	assert.Equal(t, value, header.String())
+	assert.Equal(t, "GNFD1-EDDSA, Signature=01ab, Domain=https://greenfield.example, Version=1", value)
+
+	parsed, err := ParseAuthHeader(value)
+	assert.Nil(t, err)
+	assert.Equal(t, header, parsed)
+
+	parsed, err = ParseAuthHeader("GNFD1-ECDSA,Signature=01ab")
+	assert.Nil(t, err)
+	assert.Equal(t, &AuthHeader{Algorithm: Gnfd1Ecdsa, Signature: []byte{0x01, 0xab}, Fields: map[string]string{}},
+		parsed)
+	assert.Equal(t, "GNFD1-ECDSA, Signature=01ab", parsed.String())
+
+	// the registration header of greenfield-go-sdk has the 0x prefixed signature and the escaped line breaks
+	parsed, err = ParseAuthHeader(`GNFD1-ETH-PERSONAL_SIGN,SignedMsg=https://greenfield.example wants you to sign in ` +
+		`with your BNB Greenfield account:\n0x934589F21d882C52c48aA83b6CC7a0E9668c5270\n\nURI: ` +
+		`https://greenfield.example\nVersion: 1,Signature=0x01ab`)
+	assert.Nil(t, err)
+	assert.Equal(t, Gnfd1EthPersonalSign, parsed.Algorithm)
+	assert.Equal(t, []byte{0x01, 0xab}, parsed.Signature)
+	assert.Equal(t, `https://greenfield.example wants you to sign in with your BNB Greenfield account:\n`+
+		`0x934589F21d882C52c48aA83b6CC7a0E9668c5270\n\nURI: https://greenfield.example\nVersion: 1`,
+		parsed.Fields["SignedMsg"])
+}
+
+// TestAuthHeaderRoundTrip checks the header built by Build is parsed back, and the fields which can not be carried
+// are rejected by Build
+func TestAuthHeaderRoundTrip(t *testing.T) {
+	for _, fields := range []map[string]string{
+		nil,
+		{"Domain": "https://greenfield.example/path?a"},
+		{"SignedMsg": `line1\nline2: with spaces (name: SP_001)`, "Version": "1"},
+		// the inner spaces are carried
+		{"Do main": "a b"},
+	} {
+		header := &AuthHeader{Algorithm: Gnfd1EthPersonalSign, Signature: []byte{0x01, 0xab}, Fields: fields}
+		value, err := header.Build()
+		assert.Nil(t, err)
+		parsed, err := ParseAuthHeader(value)
+		assert.Nil(t, err)
+		assert.Equal(t, header.Algorithm, parsed.Algorithm)
+		assert.Equal(t, header.Signature, parsed.Signature)
+		assert.Equal(t, len(fields), len(parsed.Fields))
+		for key, val := range fields {
+			assert.Equal(t, val, parsed.Fields[key])
+		}
+	}
+
+	for _, c := range []struct {
+		header *AuthHeader
+		field  string
+		err    error
+	}{
+		{&AuthHeader{Algorithm: "GNFD3-ECDSA", Signature: []byte{1}}, "", ErrUnknownAuthAlgorithm},
+		{&AuthHeader{Algorithm: Gnfd1Ecdsa}, AuthSignatureField, ErrInvalidAuthSignature},
+		{&AuthHeader{Algorithm: Gnfd1Ecdsa, Signature: []byte{1}, Fields: map[string]string{"Domain": "a,b"}},
+			"Domain", ErrInvalidAuthField},
+		{&AuthHeader{Algorithm: Gnfd1Ecdsa, Signature: []byte{1}, Fields: map[string]string{"Domain": "a=b"}},
+			"Domain", ErrInvalidAuthField},
+		{&AuthHeader{Algorithm: Gnfd1Ecdsa, Signature: []byte{1}, Fields: map[string]string{"Domain": ""}},
+			"Domain", ErrInvalidAuthField},
+		{&AuthHeader{Algorithm: Gnfd1Ecdsa, Signature: []byte{1}, Fields: map[string]string{"Domain": " a"}},
+			"Domain", ErrInvalidAuthField},
+		{&AuthHeader{Algorithm: Gnfd1Ecdsa, Signature: []byte{1}, Fields: map[string]string{"Domain": "a\nb"}},
+			"Domain", ErrInvalidAuthField},
+		{&AuthHeader{Algorithm: Gnfd1Ecdsa, Signature: []byte{1}, Fields: map[string]string{"": "a"}},
+			"", ErrInvalidAuthField},
+		{&AuthHeader{Algorithm: Gnfd1Ecdsa, Signature: []byte{1}, Fields: map[string]string{"a=b": "a"}},
+			"a=b", ErrInvalidAuthField},
+		{&AuthHeader{Algorithm: Gnfd1Ecdsa, Signature: []byte{1}, Fields: map[string]string{"Signature": "01"}},
+			AuthSignatureField, ErrDuplicateAuthField},
+	} {
+		value, err := c.header.Build()
+		assert.ErrorIs(t, err, c.err, c.header.Fields)
+		var headerErr *AuthHeaderError
+		assert.True(t, errors.As(err, &headerErr))
+		assert.Equal(t, c.field, headerErr.Field)
+		assert.Empty(t, value)
+	}
+}
+
+func TestParseAuthHeaderError(t *testing.T) {
+	for _, c := range []struct {
+		value string
+		field string
+		err   error
+	}{
+		{"", "", ErrMalformedAuthHeader},
+		{"GNFD3-ECDSA, Signature=01ab", "", ErrUnknownAuthAlgorithm},
+		{"gnfd1-ecdsa, Signature=01ab", "", ErrUnknownAuthAlgorithm},
+		{"GNFD1-ECDSA, Signature", "", ErrMalformedAuthHeader},
+		{"GNFD1-ECDSA, Signature=01ab,", "", ErrMalformedAuthHeader},
+		{"GNFD1-ECDSA, =01ab", "", ErrMalformedAuthHeader},
+		{"GNFD1-ECDSA", AuthSignatureField, ErrInvalidAuthSignature},
+		{"GNFD1-ECDSA, Signature=", AuthSignatureField, ErrInvalidAuthSignature},
+		{"GNFD1-ECDSA, Signature=0xzz", AuthSignatureField, ErrInvalidAuthSignature},
+		{"GNFD1-ECDSA, Signature=0x", AuthSignatureField, ErrInvalidAuthSignature},
+		{"GNFD1-ECDSA, Signature=01ab, Domain=a=b", "Domain", ErrInvalidAuthField},
+		{"GNFD1-ECDSA, Signature=01ab, Domain=", "Domain", ErrInvalidAuthField},
+		{"GNFD1-ECDSA, Signature=01ab, Domain =a", "", ErrMalformedAuthHeader},
+		{"GNFD1-ECDSA, Signature=01a", AuthSignatureField, ErrInvalidAuthSignature},
+		{"GNFD1-ECDSA, Signature=01ab, Signature=01ab", AuthSignatureField, ErrDuplicateAuthField},
+		{"GNFD1-EDDSA, Signature=01ab, Domain=a, Domain=b", "Domain", ErrDuplicateAuthField},
+	} {
+		_, err := ParseAuthHeader(c.value)
+		assert.ErrorIs(t, err, c.err, c.value)
+		var headerErr *AuthHeaderError
+		assert.True(t, errors.As(err, &headerErr), c.value)
+		assert.Equal(t, c.field, headerErr.Field, c.value)
+	}
+}
@@ -19,6 +19,12 @@ const (
 	// HTTPHeaderExpiryTimestamp defines the expiry timestamp, which is the ISO 8601 datetime string (e.g. 2021-09-30T16:25:24Z), and the maximum Timestamp since the request sent must be less than MaxExpiryAgeInSec (seven days).
 	HTTPHeaderExpiryTimestamp = "X-Gnfd-Expiry-Timestamp"
 	HTTPHeaderAuthorization   = "Authorization"
+	// HTTPHeaderAppDomain defines the domain of the dapp using the off-chain auth
+	HTTPHeaderAppDomain = "X-Gnfd-App-Domain"
+	// HTTPHeaderAppRegNonce defines the nonce of the GNFD1-EDDSA public key registration
+	HTTPHeaderAppRegNonce = "X-Gnfd-App-Reg-Nonce"
+	// HTTPHeaderAppRegPublicKey defines the EdDSA public key of the off-chain auth
+	HTTPHeaderAppRegPublicKey = "X-Gnfd-App-Reg-Public-Key"
 	// MaxExpiryAgeInSec
 	MaxExpiryAgeInSec = 3600 * 24 * 7 // 7 days
 
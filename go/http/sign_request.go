@@ -0,0 +1,160 @@
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bnb-chain/greenfield-common/go/hash"
+)
+
+// DateFormat is the format of the X-Gnfd-Date header
+const DateFormat = "20060102T150405Z"
+
+var (
+	// ErrInvalidExpiry indicates the expiry is not positive or exceeds MaxExpiryAgeInSec
+	ErrInvalidExpiry = errors.New("invalid expiry")
+	// ErrBodyNotSeekable indicates the content sha256 can not be computed since the body can be read only once
+	ErrBodyNotSeekable = errors.New("request body is not seekable")
+)
+
+// Signer signs the 32 bytes digest of the canonical request, the returned signature is [R || S || V] of
+// ECDSA-secp256k1 which can be recovered by hash.RecoverAddr. A keyring, a raw key or a test key can be the Signer.
+type Signer interface {
+	Sign(digest []byte) ([]byte, error)
+}
+
+// KeySigner is the Signer of a secp256k1 private key, which signs by hash.SignMsg
+type KeySigner struct {
+	privKey *ecdsa.PrivateKey
+}
+
+// NewKeySigner creates a KeySigner of the private key, the raw key and ethsecp256k1.PrivKey can be converted by
+// ethcrypto.ToECDSA and PrivKey.ToECDSA
+func NewKeySigner(privKey *ecdsa.PrivateKey) *KeySigner {
+	return &KeySigner{privKey: privKey}
+}
+
+func (s *KeySigner) Sign(digest []byte) ([]byte, error) {
+	return hash.SignMsg(s.privKey, digest)
+}
+
+type signOptions struct {
+	now           time.Time
+	expiry        time.Duration
+	contentSHA256 bool
+}
+
+// SignOption configures the way of SignRequest
+type SignOption func(*signOptions)
+
+// WithSignTime sets the time of X-Gnfd-Date, it is the current time by default
+func WithSignTime(now time.Time) SignOption {
+	return func(o *signOptions) {
+		o.now = now
+	}
+}
+
+// WithExpiry sets X-Gnfd-Expiry-Timestamp to the sign time plus expiry, which must not exceed MaxExpiryAgeInSec
+func WithExpiry(expiry time.Duration) SignOption {
+	return func(o *signOptions) {
+		o.expiry = expiry
+	}
+}
+
+// WithContentSHA256 sets X-Gnfd-Content-Sha256 to the hex sha256 of the body, the body must be seekable or the
+// request must have GetBody
+func WithContentSHA256() SignOption {
+	return func(o *signOptions) {
+		o.contentSHA256 = true
+	}
+}
+
+// SignRequest signs the request with the Gnfd1Ecdsa auth type, it sets the X-Gnfd-Date header and the optional
+// X-Gnfd-Expiry-Timestamp and X-Gnfd-Content-Sha256 headers, then signs the canonical request and sets the
+// Authorization header. The options are validated and the request is signed before any header is set, so the
+// headers of req are left untouched if an error is returned.
+func SignRequest(req *http.Request, signer Signer, opts ...SignOption) error {
+	o := &signOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.now.IsZero() {
+		o.now = time.Now()
+	}
+	if o.expiry < 0 || o.expiry > MaxExpiryAgeInSec*time.Second {
+		return fmt.Errorf("%w: %s exceeds %d seconds", ErrInvalidExpiry, o.expiry.String(), MaxExpiryAgeInSec)
+	}
+
+	headers := map[string]string{HTTPHeaderDate: o.now.UTC().Format(DateFormat)}
+	if o.expiry != 0 {
+		headers[HTTPHeaderExpiryTimestamp] = o.now.Add(o.expiry).UTC().Format(time.RFC3339)
+	}
+	if o.contentSHA256 {
+		contentHash, err := getContentSHA256(req)
+		if err != nil {
+			return err
+		}
+		headers[HTTPHeaderContentSHA256] = contentHash
+	}
+
+	// the canonical request is built from a copy of the headers, req is only changed after it is signed
+	signedReq := req.Clone(req.Context())
+	for key, val := range headers {
+		signedReq.Header.Set(key, val)
+	}
+	signature, err := signer.Sign(GetMsgToSignInGNFD1Auth(signedReq))
+	if err != nil {
+		return err
+	}
+	authHeader, err := (&AuthHeader{Algorithm: Gnfd1Ecdsa, Signature: signature}).Build()
+	if err != nil {
+		return err
+	}
+	headers[HTTPHeaderAuthorization] = authHeader
+
+	for key, val := range headers {
+		req.Header.Set(key, val)
+	}
+	return nil
+}
+
+// getContentSHA256 returns the hex sha256 of the request body, the seekable body is restored to its position
+func getContentSHA256(req *http.Request) (string, error) {
+	h := sha256.New()
+	switch body := req.Body.(type) {
+	case nil:
+	case io.ReadSeeker:
+		offset, err := body.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return "", err
+		}
+		if _, err = io.Copy(h, body); err != nil {
+			return "", err
+		}
+		if _, err = body.Seek(offset, io.SeekStart); err != nil {
+			return "", err
+		}
+	default:
+		if body == http.NoBody {
+			break
+		}
+		if req.GetBody == nil {
+			return "", ErrBodyNotSeekable
+		}
+		reader, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+		defer reader.Close()
+		if _, err = io.Copy(h, reader); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/eth/ethsecp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/greenfield-common/go/hash"
+)
+
+func TestSignRequest(t *testing.T) {
+	privKey := &ethsecp256k1.PrivKey{Key: ethcrypto.Keccak256([]byte("sign request test key"))}
+	addr := sdk.AccAddress(privKey.PubKey().Address())
+	ecdsaKey, err := privKey.ToECDSA()
+	assert.Nil(t, err)
+	signTime := time.Date(2023, 10, 18, 10, 10, 10, 0, time.FixedZone("UTC+8", 8*3600))
+	content := []byte("object content")
+	contentHash := sha256.Sum256(content)
+
+	req, err := http.NewRequest(http.MethodPut, "https://sp.greenfield.example/bucket/object", bytes.NewReader(content))
+	assert.Nil(t, err)
+	err = SignRequest(req, NewKeySigner(ecdsaKey), WithSignTime(signTime), WithExpiry(time.Hour), WithContentSHA256())
+	assert.Nil(t, err)
+	assert.Equal(t, "20231018T021010Z", req.Header.Get(HTTPHeaderDate))
+	assert.Equal(t, "2023-10-18T03:10:10Z", req.Header.Get(HTTPHeaderExpiryTimestamp))
+	assert.Equal(t, hex.EncodeToString(contentHash[:]), req.Header.Get(HTTPHeaderContentSHA256))
+
+	authHeader, err := ParseAuthHeader(req.Header.Get(HTTPHeaderAuthorization))
+	assert.Nil(t, err)
+	assert.Equal(t, Gnfd1Ecdsa, authHeader.Algorithm)
+	assert.Nil(t, hash.VerifySignature(GetMsgToSignInGNFD1Auth(req), authHeader.Signature, addr))
+	// the body is still readable after signing
+	body, err := io.ReadAll(req.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, content, body)
+
+	// the content hash is computed from the seekable body and restored to the position
+	path := filepath.Join(t.TempDir(), "object")
+	assert.Nil(t, os.WriteFile(path, content, 0o600))
+	f, err := os.Open(path)
+	assert.Nil(t, err)
+	defer f.Close()
+	req, err = http.NewRequest(http.MethodPut, "https://sp.greenfield.example/bucket/object", nil)
+	assert.Nil(t, err)
+	req.Body = f
+	assert.Nil(t, SignRequest(req, NewKeySigner(ecdsaKey), WithContentSHA256()))
+	assert.Equal(t, hex.EncodeToString(contentHash[:]), req.Header.Get(HTTPHeaderContentSHA256))
+	assert.Empty(t, req.Header.Get(HTTPHeaderExpiryTimestamp))
+	offset, err := f.Seek(0, io.SeekCurrent)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), offset)
+
+	// the content hash of the empty body
+	req, err = http.NewRequest(http.MethodGet, "https://sp.greenfield.example/bucket/object", nil)
+	assert.Nil(t, err)
+	assert.Nil(t, SignRequest(req, NewKeySigner(ecdsaKey), WithContentSHA256()))
+	emptyHash := sha256.Sum256(nil)
+	assert.Equal(t, hex.EncodeToString(emptyHash[:]), req.Header.Get(HTTPHeaderContentSHA256))
+
+}
+
+// TestSignRequestRejected checks a rejected call leaves the headers of the request untouched
+func TestSignRequestRejected(t *testing.T) {
+	privKey, err := ethcrypto.ToECDSA(ethcrypto.Keccak256([]byte("sign request test key")))
+	assert.Nil(t, err)
+	content := []byte("object content")
+	signErr := errors.New("mock sign error")
+
+	for _, c := range []struct {
+		signer Signer
+		body   io.Reader
+		opts   []SignOption
+		err    error
+	}{
+		{NewKeySigner(privKey), bytes.NewReader(content), []SignOption{WithExpiry(8 * 24 * time.Hour)}, ErrInvalidExpiry},
+		{NewKeySigner(privKey), bytes.NewReader(content), []SignOption{WithExpiry(-time.Hour)}, ErrInvalidExpiry},
+		{NewKeySigner(privKey), io.MultiReader(bytes.NewReader(content)), []SignOption{WithContentSHA256()},
+			ErrBodyNotSeekable},
+		{NewKeySigner(privKey), bytes.NewReader(content), []SignOption{WithContentSHA256(),
+			WithExpiry(8 * 24 * time.Hour)}, ErrInvalidExpiry},
+		{mockSigner{err: signErr}, bytes.NewReader(content), []SignOption{WithExpiry(time.Hour), WithContentSHA256()},
+			signErr},
+		{mockSigner{}, bytes.NewReader(content), []SignOption{WithExpiry(time.Hour)}, ErrInvalidAuthSignature},
+	} {
+		req, err := http.NewRequest(http.MethodPut, "https://sp.greenfield.example/bucket/object", c.body)
+		assert.Nil(t, err)
+		req.Header.Set(HTTPHeaderDate, "20231018T101010Z")
+		req.Header.Set(HTTPHeaderAuthorization, "GNFD1-ECDSA, Signature=01ab")
+		origin := req.Header.Clone()
+
+		assert.ErrorIs(t, SignRequest(req, c.signer, c.opts...), c.err)
+		assert.Equal(t, origin, req.Header)
+	}
+}
+
+type mockSigner struct {
+	err error
+}
+
+func (s mockSigner) Sign([]byte) ([]byte, error) {
+	return nil, s.err
+}
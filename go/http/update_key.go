@@ -0,0 +1,164 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMalformedUpdateKeyMsg indicates the text is not an update-key message in the format of String
+var ErrMalformedUpdateKeyMsg = errors.New("malformed update-key message")
+
+// UpdateKeySP is the storage provider the public key is registered to, Nonce is the next nonce of the
+// user account in the SP
+type UpdateKeySP struct {
+	Address string
+	Name    string
+	Nonce   int
+}
+
+// UpdateKeyMsg is the message signed by the wallet with Gnfd1EthPersonalSign auth type in the SP update_key API,
+// to register the EdDSA public key used by the off-chain auth
+type UpdateKeyMsg struct {
+	// Domain is the domain of the dapp asking for the registration
+	Domain string
+	// UserAddress is the hex address of the wallet account
+	UserAddress string
+	// PublicKey is the hex EdDSA public key to be registered
+	PublicKey string
+	ChainID   string
+	// IssuedAt and ExpirationTime are the RFC3339 time in the signed text, they are kept as they are since the
+	// dapps format the time with the local offset
+	IssuedAt       string
+	ExpirationTime string
+	// SPs are the storage providers with the nonce, they are omitted in the registration without nonce
+	SPs []UpdateKeySP
+}
+
+const (
+	updateKeyMsgHeadSuffix = " wants you to sign in with your BNB Greenfield account:"
+	updateKeyMsgPublicKey  = "Register your identity public key "
+	updateKeyMsgURI        = "URI: "
+	updateKeyMsgVersion    = "Version: 1"
+	updateKeyMsgChainID    = "Chain ID: "
+	updateKeyMsgIssuedAt   = "Issued At: "
+	updateKeyMsgExpiration = "Expiration Time: "
+	updateKeyMsgResources  = "Resources:"
+	updateKeyMsgSP         = "- SP "
+	updateKeyMsgSPName     = " (name: "
+	updateKeyMsgSPNonce    = ") with nonce: "
+)
+
+// String returns the text of the update-key message to be signed with eth personal_sign
+func (m *UpdateKeyMsg) String() string {
+	var content strings.Builder
+	content.WriteString(m.Domain + updateKeyMsgHeadSuffix + "\n")
+	content.WriteString(m.UserAddress + "\n\n")
+	content.WriteString(updateKeyMsgPublicKey + m.PublicKey + "\n\n")
+	content.WriteString(updateKeyMsgURI + m.Domain + "\n")
+	content.WriteString(updateKeyMsgVersion + "\n")
+	content.WriteString(updateKeyMsgChainID + m.ChainID + "\n")
+	content.WriteString(updateKeyMsgIssuedAt + m.IssuedAt + "\n")
+	content.WriteString(updateKeyMsgExpiration + m.ExpirationTime)
+	if len(m.SPs) > 0 {
+		content.WriteString("\n" + updateKeyMsgResources)
+		for _, sp := range m.SPs {
+			content.WriteString("\n" + updateKeyMsgSP + sp.Address + updateKeyMsgSPName + sp.Name +
+				updateKeyMsgSPNonce + strconv.Itoa(sp.Nonce))
+		}
+	}
+	return content.String()
+}
+
+// GetIssuedAt parses the RFC3339 IssuedAt
+func (m *UpdateKeyMsg) GetIssuedAt() (time.Time, error) {
+	return time.Parse(time.RFC3339, m.IssuedAt)
+}
+
+// GetExpirationTime parses the RFC3339 ExpirationTime
+func (m *UpdateKeyMsg) GetExpirationTime() (time.Time, error) {
+	return time.Parse(time.RFC3339, m.ExpirationTime)
+}
+
+// GetMsgToSignInUpdateKey returns the bytes of the update-key message, it is signed and recovered by
+// hash.SignPersonalMsg and hash.RecoverPersonalSignAddr
+func GetMsgToSignInUpdateKey(msg *UpdateKeyMsg) []byte {
+	return []byte(msg.String())
+}
+
+// ParseUpdateKeyMsg parses the signed text of the update-key message strictly, the String of the returned message
+// is the same as the text, so the signature of the text can be verified by GetMsgToSignInUpdateKey
+func ParseUpdateKeyMsg(text string) (*UpdateKeyMsg, error) {
+	// the lines before the resources are fixed, the blank lines, the URI and the version are checked by rebuilding
+	// the text at the end
+	const fixedLines = 10
+	lines := strings.Split(text, "\n")
+	if len(lines) < fixedLines {
+		return nil, fmt.Errorf("%w: %d lines", ErrMalformedUpdateKeyMsg, len(lines))
+	}
+	msg := &UpdateKeyMsg{UserAddress: lines[1]}
+	var ok bool
+	if msg.Domain, ok = strings.CutSuffix(lines[0], updateKeyMsgHeadSuffix); !ok {
+		return nil, fmt.Errorf("%w: unknown head %q", ErrMalformedUpdateKeyMsg, lines[0])
+	}
+	if msg.PublicKey, ok = strings.CutPrefix(lines[3], updateKeyMsgPublicKey); !ok {
+		return nil, fmt.Errorf("%w: no public key", ErrMalformedUpdateKeyMsg)
+	}
+	if msg.ChainID, ok = strings.CutPrefix(lines[7], updateKeyMsgChainID); !ok {
+		return nil, fmt.Errorf("%w: no chain id", ErrMalformedUpdateKeyMsg)
+	}
+	if msg.IssuedAt, ok = strings.CutPrefix(lines[8], updateKeyMsgIssuedAt); !ok {
+		return nil, fmt.Errorf("%w: no issued time", ErrMalformedUpdateKeyMsg)
+	}
+	if _, err := msg.GetIssuedAt(); err != nil {
+		return nil, fmt.Errorf("%w: issued time %q", ErrMalformedUpdateKeyMsg, msg.IssuedAt)
+	}
+	if msg.ExpirationTime, ok = strings.CutPrefix(lines[9], updateKeyMsgExpiration); !ok {
+		return nil, fmt.Errorf("%w: no expiration time", ErrMalformedUpdateKeyMsg)
+	}
+	if _, err := msg.GetExpirationTime(); err != nil {
+		return nil, fmt.Errorf("%w: expiration time %q", ErrMalformedUpdateKeyMsg, msg.ExpirationTime)
+	}
+
+	if len(lines) > fixedLines {
+		if lines[fixedLines] != updateKeyMsgResources || len(lines) == fixedLines+1 {
+			return nil, fmt.Errorf("%w: malformed resources", ErrMalformedUpdateKeyMsg)
+		}
+		for _, line := range lines[fixedLines+1:] {
+			sp, err := parseUpdateKeySP(line)
+			if err != nil {
+				return nil, err
+			}
+			msg.SPs = append(msg.SPs, sp)
+		}
+	}
+
+	if msg.String() != text {
+		return nil, fmt.Errorf("%w: not in the format of the update-key message", ErrMalformedUpdateKeyMsg)
+	}
+	return msg, nil
+}
+
+// parseUpdateKeySP parses the resource line "- SP <address> (name: <name>) with nonce: <nonce>"
+func parseUpdateKeySP(line string) (UpdateKeySP, error) {
+	rest, ok := strings.CutPrefix(line, updateKeyMsgSP)
+	if !ok {
+		return UpdateKeySP{}, fmt.Errorf("%w: unknown resource %q", ErrMalformedUpdateKeyMsg, line)
+	}
+	address, rest, ok := strings.Cut(rest, updateKeyMsgSPName)
+	if !ok {
+		return UpdateKeySP{}, fmt.Errorf("%w: no SP name in %q", ErrMalformedUpdateKeyMsg, line)
+	}
+	name, nonce, ok := strings.Cut(rest, updateKeyMsgSPNonce)
+	if !ok {
+		return UpdateKeySP{}, fmt.Errorf("%w: no SP nonce in %q", ErrMalformedUpdateKeyMsg, line)
+	}
+	sp := UpdateKeySP{Address: address, Name: name}
+	var err error
+	if sp.Nonce, err = strconv.Atoi(nonce); err != nil {
+		return UpdateKeySP{}, fmt.Errorf("%w: SP nonce %q", ErrMalformedUpdateKeyMsg, nonce)
+	}
+	return sp, nil
+}
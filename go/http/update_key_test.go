@@ -0,0 +1,123 @@
+package http
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the expected texts are generated by the update-key templates of greenfield-go-sdk
+func TestUpdateKeyMsg(t *testing.T) {
+	msg := &UpdateKeyMsg{
+		Domain:         "https://greenfield.example",
+		UserAddress:    "0x934589F21d882C52c48aA83b6CC7a0E9668c5270",
+		PublicKey:      "1d4f1cb46223f6ef2d0f507f46fefd92ec8b31fe30840b09fce4245d9dc944ac",
+		ChainID:        "5600",
+		IssuedAt:       "2023-10-18T10:00:00Z",
+		ExpirationTime: "2023-10-19T10:00:00Z",
+		SPs:            []UpdateKeySP{{Address: "0x1111111111111111111111111111111111111111", Name: "SP_001", Nonce: 3}},
+	}
+	assert.Equal(t, "https://greenfield.example wants you to sign in with your BNB Greenfield account:\n"+
+		"0x934589F21d882C52c48aA83b6CC7a0E9668c5270\n\n"+
+		"Register your identity public key 1d4f1cb46223f6ef2d0f507f46fefd92ec8b31fe30840b09fce4245d9dc944ac\n\n"+
+		"URI: https://greenfield.example\n"+
+		"Version: 1\n"+
+		"Chain ID: 5600\n"+
+		"Issued At: 2023-10-18T10:00:00Z\n"+
+		"Expiration Time: 2023-10-19T10:00:00Z\n"+
+		"Resources:\n"+
+		"- SP 0x1111111111111111111111111111111111111111 (name: SP_001) with nonce: 3", msg.String())
+	assert.Equal(t, []byte(msg.String()), GetMsgToSignInUpdateKey(msg))
+
+	msg.SPs = append(msg.SPs, UpdateKeySP{Address: "0x2222222222222222222222222222222222222222", Name: "SP_002",
+		Nonce: 0})
+	assert.Equal(t, "https://greenfield.example wants you to sign in with your BNB Greenfield account:\n"+
+		"0x934589F21d882C52c48aA83b6CC7a0E9668c5270\n\n"+
+		"Register your identity public key 1d4f1cb46223f6ef2d0f507f46fefd92ec8b31fe30840b09fce4245d9dc944ac\n\n"+
+		"URI: https://greenfield.example\n"+
+		"Version: 1\n"+
+		"Chain ID: 5600\n"+
+		"Issued At: 2023-10-18T10:00:00Z\n"+
+		"Expiration Time: 2023-10-19T10:00:00Z\n"+
+		"Resources:\n"+
+		"- SP 0x1111111111111111111111111111111111111111 (name: SP_001) with nonce: 3\n"+
+		"- SP 0x2222222222222222222222222222222222222222 (name: SP_002) with nonce: 0", msg.String())
+
+	// the message without SP has no resources, and the time with the local offset of the dapp is kept
+	msg.PublicKey = "8e851795926c1d02c435d17293eefc5cfcfd1d5bc9faa568af4079cce6afd380"
+	msg.IssuedAt = "2023-10-18T18:00:00+08:00"
+	msg.ExpirationTime = "2023-10-19T18:00:00+08:00"
+	msg.SPs = nil
+	assert.Equal(t, "https://greenfield.example wants you to sign in with your BNB Greenfield account:\n"+
+		"0x934589F21d882C52c48aA83b6CC7a0E9668c5270\n\n"+
+		"Register your identity public key 8e851795926c1d02c435d17293eefc5cfcfd1d5bc9faa568af4079cce6afd380\n\n"+
+		"URI: https://greenfield.example\n"+
+		"Version: 1\n"+
+		"Chain ID: 5600\n"+
+		"Issued At: 2023-10-18T18:00:00+08:00\n"+
+		"Expiration Time: 2023-10-19T18:00:00+08:00", msg.String())
+	issuedAt, err := msg.GetIssuedAt()
+	require.NoError(t, err)
+	assert.True(t, issuedAt.Equal(time.Date(2023, 10, 18, 10, 0, 0, 0, time.UTC)))
+}
+
+func TestParseUpdateKeyMsg(t *testing.T) {
+	const text = "https://greenfield.example wants you to sign in with your BNB Greenfield account:\n" +
+		"0x934589F21d882C52c48aA83b6CC7a0E9668c5270\n\n" +
+		"Register your identity public key 1d4f1cb46223f6ef2d0f507f46fefd92ec8b31fe30840b09fce4245d9dc944ac\n\n" +
+		"URI: https://greenfield.example\n" +
+		"Version: 1\n" +
+		"Chain ID: 5600\n" +
+		"Issued At: 2023-10-18T18:00:00+08:00\n" +
+		"Expiration Time: 2023-10-19T10:00:00Z\n" +
+		"Resources:\n" +
+		"- SP 0x1111111111111111111111111111111111111111 (name: SP_001) with nonce: 3\n" +
+		"- SP 0x2222222222222222222222222222222222222222 (name: SP 002) with nonce: 0"
+	msg, err := ParseUpdateKeyMsg(text)
+	require.NoError(t, err)
+	assert.Equal(t, &UpdateKeyMsg{
+		Domain:         "https://greenfield.example",
+		UserAddress:    "0x934589F21d882C52c48aA83b6CC7a0E9668c5270",
+		PublicKey:      "1d4f1cb46223f6ef2d0f507f46fefd92ec8b31fe30840b09fce4245d9dc944ac",
+		ChainID:        "5600",
+		IssuedAt:       "2023-10-18T18:00:00+08:00",
+		ExpirationTime: "2023-10-19T10:00:00Z",
+		SPs: []UpdateKeySP{
+			{Address: "0x1111111111111111111111111111111111111111", Name: "SP_001", Nonce: 3},
+			{Address: "0x2222222222222222222222222222222222222222", Name: "SP 002", Nonce: 0},
+		},
+	}, msg)
+	assert.Equal(t, []byte(text), GetMsgToSignInUpdateKey(msg))
+
+	// the message without resources
+	withoutSPs := text[:strings.Index(text, "\nResources:")]
+	msg, err = ParseUpdateKeyMsg(withoutSPs)
+	require.NoError(t, err)
+	assert.Empty(t, msg.SPs)
+	assert.Equal(t, withoutSPs, msg.String())
+
+	for _, malformed := range []string{
+		"",
+		strings.Replace(text, " wants you to sign in", " wants to sign in", 1),
+		strings.Replace(text, "Register your identity public key", "Register public key", 1),
+		strings.Replace(text, "URI: https://greenfield.example", "URI: https://other.example", 1),
+		strings.Replace(text, "Version: 1", "Version: 2", 1),
+		strings.Replace(text, "Chain ID", "ChainID", 1),
+		strings.Replace(text, "2023-10-18T18:00:00+08:00", "2023-10-18 18:00:00", 1),
+		strings.Replace(text, "2023-10-19T10:00:00Z", "tomorrow", 1),
+		strings.Replace(text, "Resources:", "Resource:", 1),
+		withoutSPs + "\nResources:",
+		withoutSPs + "\n",
+		strings.Replace(text, "with nonce: 3", "with nonce: 03", 1),
+		strings.Replace(text, "with nonce: 3", "with nonce: x", 1),
+		strings.Replace(text, "(name: SP_001)", "SP_001", 1),
+		strings.Replace(text, "- SP 0x1111", "- 0x1111", 1),
+		strings.Replace(text, "\n\nURI", "\nURI", 1),
+	} {
+		_, err := ParseUpdateKeyMsg(malformed)
+		assert.ErrorIs(t, err, ErrMalformedUpdateKeyMsg, malformed)
+	}
+}
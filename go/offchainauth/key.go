@@ -0,0 +1,207 @@
+package offchainauth
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"golang.org/x/crypto/blake2b"
+
+	commonhttp "github.com/bnb-chain/greenfield-common/go/http"
+)
+
+var (
+	// ErrUnsupportedAuthType indicates the auth type is neither Gnfd1Eddsa nor Gnfd2Eddsa
+	ErrUnsupportedAuthType = errors.New("unsupported off-chain auth type")
+	// ErrInvalidPublicKey indicates the EdDSA public key is not a hex public key of the auth type
+	ErrInvalidPublicKey = errors.New("invalid EdDSA public key")
+	// ErrInvalidSignature indicates the EdDSA signature of the request is not valid
+	ErrInvalidSignature = errors.New("invalid EdDSA signature")
+)
+
+// EdDSAKey is the key of the off-chain auth derived from the seed kept by the dapp, Gnfd1Eddsa uses the EdDSA of
+// the bn254 twisted edwards curve with MiMC hash, and Gnfd2Eddsa uses ed25519.
+type EdDSAKey interface {
+	// AuthType returns Gnfd1Eddsa or Gnfd2Eddsa
+	AuthType() string
+	// PublicKey returns the hex public key, which is registered to the SPs by the update-key message
+	PublicKey() string
+	// Sign signs the msg, which is usually the canonical request returned by GetMsgToSignInGNFD1Auth
+	Sign(msg []byte) ([]byte, error)
+}
+
+// NewEdDSAKey derives the EdDSA key of the auth type from the seed, the derivation is the same as greenfield-go-sdk
+func NewEdDSAKey(authType string, seed string) (EdDSAKey, error) {
+	switch authType {
+	case commonhttp.Gnfd1Eddsa:
+		privKey, err := generateGnfd1PrivateKey(seed)
+		if err != nil {
+			return nil, err
+		}
+		return &gnfd1Key{privKey: privKey}, nil
+	case commonhttp.Gnfd2Eddsa:
+		// the sha256 of the seed is the seed of the ed25519 key
+		hashedSeed := sha256.Sum256([]byte(seed))
+		return &gnfd2Key{privKey: ed25519.NewKeyFromSeed(hashedSeed[:])}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAuthType, authType)
+	}
+}
+
+type gnfd1Key struct {
+	privKey *eddsa.PrivateKey
+}
+
+func (k *gnfd1Key) AuthType() string {
+	return commonhttp.Gnfd1Eddsa
+}
+
+func (k *gnfd1Key) PublicKey() string {
+	return hex.EncodeToString(k.privKey.PublicKey.Bytes())
+}
+
+func (k *gnfd1Key) Sign(msg []byte) ([]byte, error) {
+	return k.privKey.Sign(msg, mimc.NewMiMC())
+}
+
+type gnfd2Key struct {
+	privKey ed25519.PrivateKey
+}
+
+func (k *gnfd2Key) AuthType() string {
+	return commonhttp.Gnfd2Eddsa
+}
+
+func (k *gnfd2Key) PublicKey() string {
+	return hex.EncodeToString(k.privKey.Public().(ed25519.PublicKey))
+}
+
+func (k *gnfd2Key) Sign(msg []byte) ([]byte, error) {
+	return ed25519.Sign(k.privKey, msg), nil
+}
+
+// VerifySignature verifies the signature of msg is signed by the key of the hex publicKey of the auth type
+func VerifySignature(authType string, publicKey string, msg, signature []byte) error {
+	pubKeyBytes, err := hex.DecodeString(publicKey)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidPublicKey, publicKey)
+	}
+	switch authType {
+	case commonhttp.Gnfd1Eddsa:
+		pubKey := &eddsa.PublicKey{}
+		if _, err = pubKey.SetBytes(pubKeyBytes); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidPublicKey, err.Error())
+		}
+		if ok, err := pubKey.Verify(signature, msg, mimc.NewMiMC()); err != nil || !ok {
+			return ErrInvalidSignature
+		}
+	case commonhttp.Gnfd2Eddsa:
+		if len(pubKeyBytes) != ed25519.PublicKeySize {
+			return fmt.Errorf("%w: %s", ErrInvalidPublicKey, publicKey)
+		}
+		if !ed25519.Verify(pubKeyBytes, msg, signature) {
+			return ErrInvalidSignature
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedAuthType, authType)
+	}
+	return nil
+}
+
+// SignRequest signs the request with the EdDSA key in the same way as greenfield-go-sdk, it sets the user address
+// and the dapp domain headers, and the public key header for Gnfd2Eddsa, then signs the canonical request returned
+// by GetMsgToSignInGNFD1Auth and sets the Authorization header
+func SignRequest(req *http.Request, key EdDSAKey, userAddress, domain string) error {
+	req.Header.Set(commonhttp.HTTPHeaderUserAddress, userAddress)
+	req.Header.Set(commonhttp.HTTPHeaderAppDomain, domain)
+	if key.AuthType() == commonhttp.Gnfd2Eddsa {
+		req.Header.Set(commonhttp.HTTPHeaderAppRegPublicKey, key.PublicKey())
+	}
+	signature, err := key.Sign(commonhttp.GetMsgToSignInGNFD1Auth(req))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(commonhttp.HTTPHeaderAuthorization,
+		key.AuthType()+","+commonhttp.AuthSignatureField+"="+hex.EncodeToString(signature))
+	return nil
+}
+
+// VerifyRequest verifies the EdDSA signature of the request is signed by the key of the hex publicKey
+func VerifyRequest(req *http.Request, authType string, publicKey string, signature []byte) error {
+	return VerifySignature(authType, publicKey, commonhttp.GetMsgToSignInGNFD1Auth(req), signature)
+}
+
+// generateGnfd1PrivateKey generates the Gnfd1Eddsa private key from the seed, the seed is padded or truncated to
+// 32 bytes
+func generateGnfd1PrivateKey(seed string) (*eddsa.PrivateKey, error) {
+	buf := make([]byte, 32)
+	copy(buf, seed)
+	return generateGnfd1Key(bytes.NewReader(buf))
+}
+
+// generateGnfd1Key generates the key of the bn254 twisted edwards curve, it differs from eddsa.GenerateKey of
+// gnark-crypto by pruning the scalar to 253 bits, which is what the deployed dapps and SPs use
+func generateGnfd1Key(r io.Reader) (*eddsa.PrivateKey, error) {
+	c := twistededwards.GetEdwardsCurve()
+
+	var (
+		randSrc = make([]byte, 32)
+		scalar  = make([]byte, 32)
+		pub     eddsa.PublicKey
+	)
+
+	// hash(h) = private_key || random_source, on 32 bytes each
+	seed := make([]byte, 32)
+	if _, err := r.Read(seed); err != nil {
+		return nil, err
+	}
+	h := blake2b.Sum512(seed)
+	for i := 0; i < 32; i++ {
+		randSrc[i] = h[i+32]
+	}
+
+	// prune the key, https://tools.ietf.org/html/rfc8032#section-5.1.5, key generation
+	h[0] &= 0xF8
+	h[31] &= 0x7F
+	h[31] |= 0x40
+	// convert 256 bits to 254 bits supporting bn254 curve
+	h[31] &= 0xFC
+
+	// reverse first bytes because setBytes interpret stream as big endian
+	// but in eddsa specs s is the first 32 bytes in little endian
+	for i, j := 0, fr.Bytes-1; i < fr.Bytes; i, j = i+1, j-1 {
+		scalar[i] = h[j]
+	}
+	a := new(big.Int).SetBytes(scalar)
+	for i := 253; i < 256; i++ {
+		a.SetBit(a, i, 0)
+	}
+	copy(scalar, a.FillBytes(make([]byte, 32)))
+
+	var bscalar big.Int
+	bscalar.SetBytes(scalar)
+	pub.A.ScalarMul(&c.Base, &bscalar)
+
+	var res [fr.Bytes * 3]byte
+	pubkBin := pub.A.Bytes()
+	subtle.ConstantTimeCopy(1, res[:fr.Bytes], pubkBin[:])
+	subtle.ConstantTimeCopy(1, res[fr.Bytes:2*fr.Bytes], scalar)
+	subtle.ConstantTimeCopy(1, res[2*fr.Bytes:], randSrc)
+
+	privKey := &eddsa.PrivateKey{}
+	if _, err := privKey.SetBytes(res[:]); err != nil {
+		return nil, err
+	}
+	return privKey, nil
+}
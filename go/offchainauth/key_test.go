@@ -0,0 +1,97 @@
+package offchainauth
+
+import (
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	commonhttp "github.com/bnb-chain/greenfield-common/go/http"
+)
+
+// the vectors are generated by the off-chain auth of greenfield-go-sdk v1.6.0 with the seed of testSeed
+const (
+	testSeed        = "greenfield off-chain auth test seed"
+	testDomain      = "https://greenfield.example"
+	testUserAddress = "0x934589F21d882C52c48aA83b6CC7a0E9668c5270"
+	// testRequestMsg is GetMsgToSignInGNFD1Auth of testRequest signed by the user
+	testRequestMsg = "e20888e581ee7961ef73e7537fc022707d2bfd5e22afa66e070291b8fc3bda38"
+
+	testGnfd1PublicKey = "1d4f1cb46223f6ef2d0f507f46fefd92ec8b31fe30840b09fce4245d9dc944ac"
+	testGnfd1Auth      = "GNFD1-EDDSA,Signature=a4fef123803012772c1e1cccaaf7b84e1a42e6102a0a712ee8615b81234640a5" +
+		"01803f9ad0115822f1b52fb266cb2aecc4aa1a7f57d83186e9e2fcb276ea33ad"
+	testGnfd2PublicKey = "8e851795926c1d02c435d17293eefc5cfcfd1d5bc9faa568af4079cce6afd380"
+	testGnfd2Auth      = "GNFD2-EDDSA,Signature=3e24d7d611013b2f5a75c5c2c3146724bca1d4a62a9938272ddb7ad6087f1fa9" +
+		"868723c5f3ef7d16bd3f80d1a12097299aeeeb6c8d2226b78220f30bb731ae0f"
+)
+
+func testRequest(t *testing.T) *http.Request {
+	req, err := http.NewRequest(http.MethodGet, "https://sp.greenfield.example/bucket/object?offset=1", nil)
+	assert.Nil(t, err)
+	req.Header.Set(commonhttp.HTTPHeaderDate, "20231018T101010Z")
+	return req
+}
+
+func TestEdDSAKey(t *testing.T) {
+	msg, _ := hex.DecodeString(testRequestMsg)
+	for _, c := range []struct {
+		authType  string
+		publicKey string
+		auth      string
+	}{
+		{commonhttp.Gnfd1Eddsa, testGnfd1PublicKey, testGnfd1Auth},
+		{commonhttp.Gnfd2Eddsa, testGnfd2PublicKey, testGnfd2Auth},
+	} {
+		key, err := NewEdDSAKey(c.authType, testSeed)
+		assert.Nil(t, err)
+		assert.Equal(t, c.authType, key.AuthType())
+		assert.Equal(t, c.publicKey, key.PublicKey())
+
+		signature, err := key.Sign(msg)
+		assert.Nil(t, err)
+		assert.Equal(t, c.auth, c.authType+",Signature="+hex.EncodeToString(signature))
+		assert.Nil(t, VerifySignature(c.authType, c.publicKey, msg, signature))
+
+		// the signature of another msg or another key fails
+		assert.ErrorIs(t, VerifySignature(c.authType, c.publicKey, msg[1:], signature), ErrInvalidSignature)
+		otherKey, err := NewEdDSAKey(c.authType, "other seed")
+		assert.Nil(t, err)
+		assert.ErrorIs(t, VerifySignature(c.authType, otherKey.PublicKey(), msg, signature), ErrInvalidSignature)
+		assert.ErrorIs(t, VerifySignature(c.authType, "0x01", msg, signature), ErrInvalidPublicKey)
+	}
+
+	_, err := NewEdDSAKey(commonhttp.Gnfd1Ecdsa, testSeed)
+	assert.ErrorIs(t, err, ErrUnsupportedAuthType)
+	assert.ErrorIs(t, VerifySignature(commonhttp.Gnfd1Ecdsa, testGnfd2PublicKey, msg, nil), ErrUnsupportedAuthType)
+}
+
+func TestSignAndVerifyRequest(t *testing.T) {
+	for _, c := range []struct {
+		authType string
+		auth     string
+	}{
+		{commonhttp.Gnfd1Eddsa, testGnfd1Auth},
+		{commonhttp.Gnfd2Eddsa, testGnfd2Auth},
+	} {
+		key, err := NewEdDSAKey(c.authType, testSeed)
+		assert.Nil(t, err)
+		req := testRequest(t)
+		assert.Nil(t, SignRequest(req, key, testUserAddress, testDomain))
+		assert.Equal(t, testUserAddress, req.Header.Get(commonhttp.HTTPHeaderUserAddress))
+		assert.Equal(t, testDomain, req.Header.Get(commonhttp.HTTPHeaderAppDomain))
+		assert.Equal(t, testRequestMsg, hex.EncodeToString(commonhttp.GetMsgToSignInGNFD1Auth(req)))
+		assert.Equal(t, c.auth, req.Header.Get(commonhttp.HTTPHeaderAuthorization))
+		if c.authType == commonhttp.Gnfd2Eddsa {
+			assert.Equal(t, testGnfd2PublicKey, req.Header.Get(commonhttp.HTTPHeaderAppRegPublicKey))
+		}
+
+		authHeader, err := commonhttp.ParseAuthHeader(req.Header.Get(commonhttp.HTTPHeaderAuthorization))
+		assert.Nil(t, err)
+		assert.Equal(t, c.authType, authHeader.Algorithm)
+		assert.Nil(t, VerifyRequest(req, c.authType, key.PublicKey(), authHeader.Signature))
+
+		req.Header.Set(commonhttp.HTTPHeaderDate, "20231018T101011Z")
+		assert.ErrorIs(t, VerifyRequest(req, c.authType, key.PublicKey(), authHeader.Signature), ErrInvalidSignature)
+	}
+}
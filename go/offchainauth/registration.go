@@ -0,0 +1,139 @@
+package offchainauth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/bnb-chain/greenfield-common/go/hash"
+	commonhttp "github.com/bnb-chain/greenfield-common/go/http"
+)
+
+const registrationSignedMsgField = "SignedMsg"
+
+var (
+	// ErrInvalidRegistration indicates the registration message is malformed or expired
+	ErrInvalidRegistration = errors.New("invalid registration message")
+	// ErrNonceMismatch indicates the nonce of the registration is not the expected nonce of the SP
+	ErrNonceMismatch = errors.New("registration nonce mismatch")
+)
+
+// NewRegistrationMsg returns the update-key message registering the public key of the EdDSA key, which is signed by
+// the wallet with eth personal_sign. The SPs with the next nonce are only carried by Gnfd1Eddsa, they must be empty
+// for Gnfd2Eddsa. The time is formatted in RFC3339 with its own offset as greenfield-go-sdk does.
+func NewRegistrationMsg(key EdDSAKey, domain, userAddress, chainID string, issuedAt, expirationTime time.Time,
+	sps []commonhttp.UpdateKeySP,
+) (*commonhttp.UpdateKeyMsg, error) {
+	switch key.AuthType() {
+	case commonhttp.Gnfd1Eddsa:
+		if len(sps) == 0 {
+			return nil, fmt.Errorf("%w: no SP nonce in %s", ErrInvalidRegistration, key.AuthType())
+		}
+	case commonhttp.Gnfd2Eddsa:
+		if len(sps) != 0 {
+			return nil, fmt.Errorf("%w: SP nonce in %s", ErrInvalidRegistration, key.AuthType())
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAuthType, key.AuthType())
+	}
+	return &commonhttp.UpdateKeyMsg{
+		Domain:         domain,
+		UserAddress:    userAddress,
+		PublicKey:      key.PublicKey(),
+		ChainID:        chainID,
+		IssuedAt:       issuedAt.Format(time.RFC3339),
+		ExpirationTime: expirationTime.Format(time.RFC3339),
+		SPs:            sps,
+	}, nil
+}
+
+// GetRegistrationAuthorization returns the Authorization header of the update-key request in the same format as
+// greenfield-go-sdk, which carries the message with the escaped line breaks and the 0x prefixed signature
+func GetRegistrationAuthorization(msg *commonhttp.UpdateKeyMsg, signature []byte) string {
+	authorization := commonhttp.Gnfd1EthPersonalSign + "," + registrationSignedMsgField + "=" + msg.String() + "," +
+		commonhttp.AuthSignatureField + "=" + hexutil.Encode(signature)
+	return strings.ReplaceAll(authorization, "\n", "\\n")
+}
+
+// ParseRegistrationAuthorization parses the Authorization header of the update-key request, it returns the signed
+// text of the update-key message with the line breaks unescaped and the signature of the wallet
+func ParseRegistrationAuthorization(authorization string) (string, []byte, error) {
+	authHeader, err := commonhttp.ParseAuthHeader(authorization)
+	if err != nil {
+		return "", nil, err
+	}
+	if authHeader.Algorithm != commonhttp.Gnfd1EthPersonalSign {
+		return "", nil, fmt.Errorf("%w: auth type %s", ErrInvalidRegistration, authHeader.Algorithm)
+	}
+	signedMsg, ok := authHeader.Fields[registrationSignedMsgField]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: no %s", ErrInvalidRegistration, registrationSignedMsgField)
+	}
+	return strings.ReplaceAll(signedMsg, "\\n", "\n"), authHeader.Signature, nil
+}
+
+// VerifyGnfd1Registration verifies the signed text of the Gnfd1Eddsa registration is signed by the user, it must be
+// valid at now and carry the expected nonce of the SP of spAddress. The parsed message is returned.
+func VerifyGnfd1Registration(signedMsg string, signature []byte, spAddress string, nonce int,
+	now time.Time,
+) (*commonhttp.UpdateKeyMsg, error) {
+	msg, err := verifyRegistration(signedMsg, signature, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, sp := range msg.SPs {
+		if !strings.EqualFold(sp.Address, spAddress) {
+			continue
+		}
+		if sp.Nonce != nonce {
+			return nil, fmt.Errorf("%w: expected %d, got %d", ErrNonceMismatch, nonce, sp.Nonce)
+		}
+		return msg, nil
+	}
+	return nil, fmt.Errorf("%w: SP %s not found", ErrInvalidRegistration, spAddress)
+}
+
+// VerifyGnfd2Registration verifies the signed text of the Gnfd2Eddsa registration is signed by the user, it must be
+// valid at now and carry no SP nonce. The parsed message is returned.
+func VerifyGnfd2Registration(signedMsg string, signature []byte, now time.Time) (*commonhttp.UpdateKeyMsg, error) {
+	msg, err := verifyRegistration(signedMsg, signature, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(msg.SPs) != 0 {
+		return nil, fmt.Errorf("%w: SP nonce in %s", ErrInvalidRegistration, commonhttp.Gnfd2Eddsa)
+	}
+	return msg, nil
+}
+
+// verifyRegistration parses the signed text, checks the validity period of the registration and verifies the
+// personal_sign signature of the user over the text as it is
+func verifyRegistration(signedMsg string, signature []byte, now time.Time) (*commonhttp.UpdateKeyMsg, error) {
+	msg, err := commonhttp.ParseUpdateKeyMsg(signedMsg)
+	if err != nil {
+		return nil, err
+	}
+	// the time has been checked by ParseUpdateKeyMsg
+	issuedAt, _ := msg.GetIssuedAt()
+	expirationTime, _ := msg.GetExpirationTime()
+	if now.Before(issuedAt) || now.After(expirationTime) {
+		return nil, fmt.Errorf("%w: not valid at %s", ErrInvalidRegistration, now.UTC().Format(time.RFC3339))
+	}
+	if expirationTime.Sub(issuedAt) > commonhttp.MaxExpiryAgeInSec*time.Second {
+		return nil, fmt.Errorf("%w: expiration time exceeds %d seconds", ErrInvalidRegistration,
+			commonhttp.MaxExpiryAgeInSec)
+	}
+	if !common.IsHexAddress(msg.UserAddress) {
+		return nil, fmt.Errorf("%w: invalid user address %s", ErrInvalidRegistration, msg.UserAddress)
+	}
+	userAddr := sdk.AccAddress(common.HexToAddress(msg.UserAddress).Bytes())
+	if err := hash.VerifyPersonalSignature([]byte(signedMsg), signature, userAddr); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
@@ -0,0 +1,134 @@
+package offchainauth
+
+import (
+	"crypto/ecdsa"
+	"strings"
+	"testing"
+	"time"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-common/go/hash"
+	commonhttp "github.com/bnb-chain/greenfield-common/go/http"
+)
+
+// the authorizations are generated by RegisterEDDSAPublicKey and RegisterEDDSAPublicKeyV2 of greenfield-go-sdk
+// v1.6.0 with the wallet of testWallet, which has the fixed chain id 5600 and SP name SP_001
+const (
+	testGnfd1RegistrationAuth = `GNFD1-ETH-PERSONAL_SIGN,SignedMsg=https://greenfield.example wants you to sign in ` +
+		`with your BNB Greenfield account:\n0x934589F21d882C52c48aA83b6CC7a0E9668c5270\n\nRegister your identity ` +
+		`public key 1d4f1cb46223f6ef2d0f507f46fefd92ec8b31fe30840b09fce4245d9dc944ac\n\nURI: ` +
+		`https://greenfield.example\nVersion: 1\nChain ID: 5600\nIssued At: 2023-10-18T10:00:00Z\nExpiration Time: ` +
+		`2023-10-19T10:00:00Z\nResources:\n- SP 0x1111111111111111111111111111111111111111 (name: SP_001) with ` +
+		`nonce: 3,Signature=0x27decca24c05c48a5dbb057b5a858ec65d35749884b831ddbef09d0e965a28727b4bd265a4b229f1f10cca9` +
+		`379a4ef9b00ccfd01d00e017474a6d3ddb3cb78f901`
+	testGnfd2RegistrationAuth = `GNFD1-ETH-PERSONAL_SIGN,SignedMsg=https://greenfield.example wants you to sign in ` +
+		`with your BNB Greenfield account:\n0x934589F21d882C52c48aA83b6CC7a0E9668c5270\n\nRegister your identity ` +
+		`public key 8e851795926c1d02c435d17293eefc5cfcfd1d5bc9faa568af4079cce6afd380\n\nURI: ` +
+		`https://greenfield.example\nVersion: 1\nChain ID: 5600\nIssued At: 2023-10-18T10:00:00Z\nExpiration Time: ` +
+		`2023-10-19T10:00:00Z,Signature=0x63b68e1373e6312b7bbee0e903f2b6e1eb5483b22666f952a0c70f8969fa725e5fbaa27d` +
+		`cc3c31a09ff9a6bdbf05fc1465932d2fd0499c7ddb828e0a07f025b200`
+)
+
+func testWallet() *ecdsa.PrivateKey {
+	return ethcrypto.ToECDSAUnsafe(ethcrypto.Keccak256([]byte("off-chain auth test wallet")))
+}
+
+func TestRegistration(t *testing.T) {
+	issuedAt := time.Date(2023, 10, 18, 10, 0, 0, 0, time.UTC)
+	expirationTime := issuedAt.Add(24 * time.Hour)
+	now := issuedAt.Add(time.Hour)
+	sps := []commonhttp.UpdateKeySP{{Address: "0x1111111111111111111111111111111111111111", Name: "SP_001", Nonce: 3}}
+
+	gnfd1Key, err := NewEdDSAKey(commonhttp.Gnfd1Eddsa, testSeed)
+	require.NoError(t, err)
+	msg, err := NewRegistrationMsg(gnfd1Key, testDomain, testUserAddress, "5600", issuedAt, expirationTime, sps)
+	require.NoError(t, err)
+	sig, err := hash.SignPersonalMsg(testWallet(), commonhttp.GetMsgToSignInUpdateKey(msg))
+	require.NoError(t, err)
+	// the V of the wallet signature of the sdk is 0 or 1
+	sig[64] -= 27
+	assert.Equal(t, testGnfd1RegistrationAuth, GetRegistrationAuthorization(msg, sig))
+
+	// the SP verifies the signed text carried by the Authorization header
+	signedMsg, signature, err := ParseRegistrationAuthorization(testGnfd1RegistrationAuth)
+	require.NoError(t, err)
+	assert.Equal(t, msg.String(), signedMsg)
+	assert.Equal(t, sig, signature)
+	verified, err := VerifyGnfd1Registration(signedMsg, signature, sps[0].Address, 3, now)
+	require.NoError(t, err)
+	assert.Equal(t, msg, verified)
+	_, err = VerifyGnfd1Registration(signedMsg, signature, sps[0].Address, 4, now)
+	assert.ErrorIs(t, err, ErrNonceMismatch)
+	_, err = VerifyGnfd1Registration(signedMsg, signature, "0x3333333333333333333333333333333333333333", 3, now)
+	assert.ErrorIs(t, err, ErrInvalidRegistration)
+	_, err = VerifyGnfd1Registration(signedMsg, signature, sps[0].Address, 3, expirationTime.Add(time.Second))
+	assert.ErrorIs(t, err, ErrInvalidRegistration)
+	// the nonce can not be changed without the user signature
+	_, err = VerifyGnfd1Registration(strings.Replace(signedMsg, "with nonce: 3", "with nonce: 4", 1), signature,
+		sps[0].Address, 4, now)
+	assert.ErrorIs(t, err, hash.ErrSignatureMismatch)
+	_, err = VerifyGnfd1Registration(signedMsg+"\n", signature, sps[0].Address, 3, now)
+	assert.ErrorIs(t, err, commonhttp.ErrMalformedUpdateKeyMsg)
+
+	gnfd2Key, err := NewEdDSAKey(commonhttp.Gnfd2Eddsa, testSeed)
+	require.NoError(t, err)
+	msg, err = NewRegistrationMsg(gnfd2Key, testDomain, testUserAddress, "5600", issuedAt, expirationTime, nil)
+	require.NoError(t, err)
+	signedMsg, signature, err = ParseRegistrationAuthorization(testGnfd2RegistrationAuth)
+	require.NoError(t, err)
+	assert.Equal(t, testGnfd2RegistrationAuth, GetRegistrationAuthorization(msg, signature))
+	verified, err = VerifyGnfd2Registration(signedMsg, signature, now)
+	require.NoError(t, err)
+	assert.Equal(t, msg, verified)
+	otherWallet := ethcrypto.ToECDSAUnsafe(ethcrypto.Keccak256([]byte("other wallet")))
+	otherSig, err := hash.SignPersonalMsg(otherWallet, []byte(signedMsg))
+	require.NoError(t, err)
+	_, err = VerifyGnfd2Registration(signedMsg, otherSig, now)
+	assert.ErrorIs(t, err, hash.ErrSignatureMismatch)
+	// the Gnfd1Eddsa registration with nonce is not a Gnfd2Eddsa registration
+	gnfd1Msg, gnfd1Sig, err := ParseRegistrationAuthorization(testGnfd1RegistrationAuth)
+	require.NoError(t, err)
+	_, err = VerifyGnfd2Registration(gnfd1Msg, gnfd1Sig, now)
+	assert.ErrorIs(t, err, ErrInvalidRegistration)
+
+	msg.ExpirationTime = issuedAt.Add(8 * 24 * time.Hour).Format(time.RFC3339)
+	longSig, err := hash.SignPersonalMsg(testWallet(), commonhttp.GetMsgToSignInUpdateKey(msg))
+	require.NoError(t, err)
+	_, err = VerifyGnfd2Registration(msg.String(), longSig, now)
+	assert.ErrorIs(t, err, ErrInvalidRegistration)
+
+	_, err = NewRegistrationMsg(gnfd2Key, testDomain, testUserAddress, "5600", issuedAt, expirationTime, sps)
+	assert.ErrorIs(t, err, ErrInvalidRegistration)
+	_, err = NewRegistrationMsg(gnfd1Key, testDomain, testUserAddress, "5600", issuedAt, expirationTime, nil)
+	assert.ErrorIs(t, err, ErrInvalidRegistration)
+
+	_, _, err = ParseRegistrationAuthorization(testGnfd1Auth)
+	assert.ErrorIs(t, err, ErrInvalidRegistration)
+}
+
+// TestRegistrationLocalTime checks the registration signed by the dapp out of UTC, whose time has the local offset
+// as time.Now().Format(time.RFC3339) of greenfield-go-sdk, is verified by the signed text
+func TestRegistrationLocalTime(t *testing.T) {
+	local := time.FixedZone("UTC+8", 8*3600)
+	issuedAt := time.Date(2023, 10, 18, 18, 0, 0, 0, local)
+	gnfd2Key, err := NewEdDSAKey(commonhttp.Gnfd2Eddsa, testSeed)
+	require.NoError(t, err)
+	msg, err := NewRegistrationMsg(gnfd2Key, testDomain, testUserAddress, "5600", issuedAt,
+		issuedAt.Add(24*time.Hour), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "2023-10-18T18:00:00+08:00", msg.IssuedAt)
+	assert.Equal(t, "2023-10-19T18:00:00+08:00", msg.ExpirationTime)
+	sig, err := hash.SignPersonalMsg(testWallet(), commonhttp.GetMsgToSignInUpdateKey(msg))
+	require.NoError(t, err)
+
+	signedMsg, signature, err := ParseRegistrationAuthorization(GetRegistrationAuthorization(msg, sig))
+	require.NoError(t, err)
+	verified, err := VerifyGnfd2Registration(signedMsg, signature, issuedAt.UTC().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, msg, verified)
+	_, err = VerifyGnfd2Registration(signedMsg, signature, issuedAt.Add(-time.Second))
+	assert.ErrorIs(t, err, ErrInvalidRegistration)
+}
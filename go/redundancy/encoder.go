@@ -0,0 +1,144 @@
+package redundancy
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+type encoderKey struct {
+	dataShards   int
+	parityShards int
+	shardSize    int
+}
+
+// maxCachedEncoders bounds the number of the cached encoders, the encoders of the full segments are used by most of
+// the segments and stay in the cache, while the encoders of the various tail segment sizes are evicted
+const maxCachedEncoders = 32
+
+// encoderLRU caches the reed-solomon encoders by the geometry and the shard size, the least recently used one is
+// evicted once the cache is full. The encoders are safe for concurrent use.
+type encoderLRU struct {
+	mtx      sync.Mutex
+	capacity int
+	entries  map[encoderKey]*list.Element
+	order    *list.List
+}
+
+type encoderEntry struct {
+	key     encoderKey
+	encoder reedsolomon.Encoder
+}
+
+func newEncoderLRU(capacity int) *encoderLRU {
+	return &encoderLRU{capacity: capacity, entries: make(map[encoderKey]*list.Element), order: list.New()}
+}
+
+func (c *encoderLRU) get(key encoderKey) (reedsolomon.Encoder, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*encoderEntry).encoder, true
+}
+
+// add caches the encoder of the key and returns the cached one, which is the encoder added concurrently if any
+func (c *encoderLRU) add(key encoderKey, encoder reedsolomon.Encoder) reedsolomon.Encoder {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		return elem.Value.(*encoderEntry).encoder
+	}
+	c.entries[key] = c.order.PushFront(&encoderEntry{key: key, encoder: encoder})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*encoderEntry).key)
+	}
+	return encoder
+}
+
+func (c *encoderLRU) len() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.order.Len()
+}
+
+var encoderCache = newEncoderLRU(maxCachedEncoders)
+
+func cachedEncoder(dataShards, parityShards, shardSize int) (reedsolomon.Encoder, error) {
+	key := encoderKey{dataShards: dataShards, parityShards: parityShards, shardSize: shardSize}
+	if encoder, ok := encoderCache.get(key); ok {
+		return encoder, nil
+	}
+	encoder, err := reedsolomon.New(dataShards, parityShards, reedsolomon.WithAutoGoroutines(shardSize))
+	if err != nil {
+		return nil, err
+	}
+	return encoderCache.add(key, encoder), nil
+}
+
+// RawSegmentEncoder erasure encodes the segments into the shards backed by one reused buffer, so encoding the
+// segments of the same size allocates nothing. The shards are the same as EncodeRawSegment.
+// It is not safe for concurrent use.
+type RawSegmentEncoder struct {
+	dataShards   int
+	parityShards int
+	buffer       []byte
+	shards       [][]byte
+}
+
+// NewRawSegmentEncoder creates a RawSegmentEncoder of the EC geometry
+func NewRawSegmentEncoder(dataShards, parityShards int) (*RawSegmentEncoder, error) {
+	if dataShards <= 0 || parityShards < 0 {
+		return nil, reedsolomon.ErrInvShardNum
+	}
+	if dataShards+parityShards > 256 {
+		return nil, reedsolomon.ErrMaxShardNum
+	}
+	return &RawSegmentEncoder{
+		dataShards:   dataShards,
+		parityShards: parityShards,
+		shards:       make([][]byte, dataShards+parityShards),
+	}, nil
+}
+
+// Encode erasure encodes the content and returns the shards in orders, the shards are only valid until the next call
+// of Encode since their buffer is reused
+func (e *RawSegmentEncoder) Encode(content []byte) ([][]byte, error) {
+	if len(content) == 0 {
+		for index := range e.shards {
+			e.shards[index] = nil
+		}
+		return e.shards, nil
+	}
+
+	shardSize := (len(content) + e.dataShards - 1) / e.dataShards
+	encoder, err := cachedEncoder(e.dataShards, e.parityShards, shardSize)
+	if err != nil {
+		return nil, err
+	}
+	totalSize := shardSize * len(e.shards)
+	if cap(e.buffer) < totalSize {
+		e.buffer = make([]byte, totalSize)
+	}
+	e.buffer = e.buffer[:totalSize]
+	n := copy(e.buffer, content)
+	// the last data shard is padded with zeros, the parity shards are overwritten by the encoding
+	padding := e.buffer[n : shardSize*e.dataShards]
+	for index := range padding {
+		padding[index] = 0
+	}
+	for index := range e.shards {
+		e.shards[index] = e.buffer[index*shardSize : (index+1)*shardSize : (index+1)*shardSize]
+	}
+	if err = encoder.Encode(e.shards); err != nil {
+		return nil, err
+	}
+	return e.shards, nil
+}
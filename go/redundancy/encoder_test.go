@@ -0,0 +1,89 @@
+package redundancy
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRawSegmentEncoder(t *testing.T) {
+	encoder, err := NewRawSegmentEncoder(DataBlocks, ParityBlocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the sizes are not in order to check the reused buffer is padded correctly
+	for _, size := range []int{1024 * 1024, 1, 1024*1024 - 3, 0, 4097, 1024 * 1024} {
+		content := initSegmentData(size)
+		expected, err := EncodeRawSegment(append([]byte{}, content...), DataBlocks, ParityBlocks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shards, err := encoder.Encode(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(shards) != len(expected) {
+			t.Fatalf("shards number mismatch of size %d", size)
+		}
+		for index := range shards {
+			if !bytes.Equal(shards[index], expected[index]) {
+				t.Errorf("shard %d mismatch of size %d", index, size)
+			}
+		}
+	}
+
+	if _, err = NewRawSegmentEncoder(0, ParityBlocks); err == nil {
+		t.Errorf("invalid geometry should fail")
+	}
+}
+
+func TestEncoderCacheBounded(t *testing.T) {
+	encoder, err := NewRawSegmentEncoder(DataBlocks, ParityBlocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const segmentSize = 64 * 1024
+	fullKey := encoderKey{dataShards: DataBlocks, parityShards: ParityBlocks, shardSize: segmentSize / DataBlocks}
+	// the segments of an object are mostly full, its tail segment is of a various size
+	for tailSize := 1; tailSize <= 1000; tailSize++ {
+		if _, err = encoder.Encode(initSegmentData(segmentSize)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = encoder.Encode(initSegmentData(tailSize * DataBlocks)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if encoderCache.len() > maxCachedEncoders {
+		t.Fatalf("the cached encoders %d exceed %d", encoderCache.len(), maxCachedEncoders)
+	}
+	if _, ok := encoderCache.get(fullKey); !ok {
+		t.Fatal("the encoder of the full segments is evicted")
+	}
+}
+
+func BenchmarkEncodeRawSegment(b *testing.B) {
+	content := initSegmentData(1024 * 1024)
+	b.SetBytes(int64(len(content)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := EncodeRawSegment(content[:len(content):len(content)], DataBlocks, ParityBlocks); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRawSegmentEncoder(b *testing.B) {
+	content := initSegmentData(1024 * 1024)
+	encoder, err := NewRawSegmentEncoder(DataBlocks, ParityBlocks)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(content)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := encoder.Encode(content); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
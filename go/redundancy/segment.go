@@ -3,6 +3,7 @@ package redundancy
 import (
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 
@@ -45,8 +46,20 @@ func NewSegment(size int64, content []byte, segmentID int, objectID string) *Seg
 	}
 }
 
+// EncodeObserver receives the timing of the erasure encoding, it should not change the encoding results
+type EncodeObserver interface {
+	// OnSegmentEncoded is called after the segment of segmentID is encoded
+	OnSegmentEncoded(segmentID int, duration time.Duration)
+}
+
 // EncodeSegment encode to segment, return the piece content and the meta of pieces
 func EncodeSegment(s *Segment) ([]*PieceObject, error) {
+	return EncodeSegmentWithObserver(s, nil)
+}
+
+// EncodeSegmentWithObserver is the same as EncodeSegment, and notifies the observer if it is not nil
+func EncodeSegmentWithObserver(s *Segment, observer EncodeObserver) ([]*PieceObject, error) {
+	start := time.Now()
 	encoder, err := erasure.NewRSEncoder(defaultECConfig.dataBlocks, defaultECConfig.parityBlocks, s.SegmentSize)
 	if err != nil {
 		log.Error().Msg("new RSEncoder fail" + err.Error())
@@ -68,6 +81,9 @@ func EncodeSegment(s *Segment) ([]*PieceObject, error) {
 		}
 		pieceObjectList[index] = piece
 	}
+	if observer != nil {
+		observer.OnSegmentEncoded(s.SegmentID, time.Since(start))
+	}
 
 	return pieceObjectList, nil
 }
@@ -113,6 +129,15 @@ func DecodeSegment(pieces []*PieceObject, segmentSize int64) (*Segment, error) {
 
 // EncodeRawSegment encode a raw byte array and return erasure encoded shards in orders
 func EncodeRawSegment(content []byte, dataShards, parityShards int) ([][]byte, error) {
+	return EncodeRawSegmentWithObserver(content, 0, dataShards, parityShards, nil)
+}
+
+// EncodeRawSegmentWithObserver is the same as EncodeRawSegment, and notifies the observer with the segmentID
+// if it is not nil
+func EncodeRawSegmentWithObserver(content []byte, segmentID int, dataShards, parityShards int,
+	observer EncodeObserver,
+) ([][]byte, error) {
+	start := time.Now()
 	encoder, err := erasure.NewRSEncoder(dataShards, parityShards, int64(len(content)))
 	if err != nil {
 		log.Error().Msg("new RSEncoder fail:" + err.Error())
@@ -122,6 +147,9 @@ func EncodeRawSegment(content []byte, dataShards, parityShards int) ([][]byte, e
 	if err != nil {
 		return nil, err
 	}
+	if observer != nil {
+		observer.OnSegmentEncoded(segmentID, time.Since(start))
+	}
 	return shards, nil
 }
 